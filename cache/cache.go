@@ -1,8 +1,12 @@
 package cache
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // The implementation uses two main data structures:
@@ -15,52 +19,185 @@ import (
 // - Simplifying head/tail operations
 // Note on circularity: It may affect the readability of some code parts, obscure parts are well commented and documented
 //
+// Nodes are recycled through a sync.Pool: a node removed from the DLL is cleared and reused by a later
+// insertion, so a full cache under churn doesn't allocate. No node must be used once released.
+//
 // All operations are O(1) time complexity. Thread safety is ensured through a cache-wide mutex due to operations affecting the overall DS
+// (a read-write mutex: Get answers under the read lock when the access changes nothing but counters,
+// see GetCtx, and takes the write lock otherwise)
 
 // A node in the Circular-DLL
-type cacheNode struct {
-	prev  *cacheNode
-	next  *cacheNode
-	value string
-	key   string
+type cacheNode[K comparable, V any] struct {
+	prev  *cacheNode[K, V]
+	next  *cacheNode[K, V]
+	value V
+	key   K
+
+	// insertion sequence number, see InsertionOrder
+	seq uint64
+	// last Get or Set, only tracked WithMaxIdle
+	lastAccessedAt time.Time
+	// deadline set by SetWithTTL, zero for entries that don't expire
+	expiresAt time.Time
+	// eviction tier, see SetWithPriority
+	priority int
+	// Gets since the last promotion, only tracked WithPromotionBatching.
+	// Gets under the read lock count concurrently, with atomic adds
+	pendingHits int32
+	// Sets and Gets of the entry, only tracked under PolicyLFU
+	frequency uint64
+	// generation of the cache the value was set in, see Rotate
+	generation uint64
 }
 
-type LruCache struct {
-	mutex    *sync.Mutex
-	head     *cacheNode
+// Entry is a key-value pair copied out of the cache
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// LruCache is a cache of values of type V under keys of type K, evicting the least recently used
+// entry once full
+type LruCache[K comparable, V any] struct {
+	mutex    rwLocker
+	head     *cacheNode[K, V]
 	capacity int
-	store    map[string]*cacheNode
+	store    map[K]*cacheNode[K, V]
+	// released nodes, reused by addToHead
+	nodePool *sync.Pool
+
+	// byte budget of a NewSizeCache, 0 for an entry count capacity only
+	maxBytes int64
+	// total size of the keys and values held, see entrySize
+	currentBytes int64
+
+	// last insertion sequence number handed out
+	seq uint64
+	// incremented by Rotate
+	generation uint64
+	// whether updating a key gives it a new insertion sequence number
+	refreshSeqOnUpdate bool
+	// validate after every operation, see WithDebugIntegrityChecks
+	debugChecks bool
+	// set between SuspendEviction and ResumeEviction
+	evictionSuspended bool
+	// promote on every nth Get only, see WithPromotionBatching
+	promotionBatch int
+	// eviction strategy and LFU tie-break, see WithPolicy and WithLFUTieBreak
+	policy      Policy
+	lfuTieBreak LFUTieBreak
+
+	// dependency index, see SetWithDeps
+	dependents map[K]map[K]struct{}
+	dependsOn  map[K][]K
+
+	// entry count per non-zero priority, see SetWithPriority
+	priorities map[int]int
+
+	// hit, miss and eviction counts, see Stats
+	stats cacheStats
+
+	// optional features, nil/zero when disabled
+	onMiss     func(key K)
+	onEvict    func(key K, value V)
+	now        func() time.Time
+	maxIdle    time.Duration
+	opsRate    *opsRate
+	fairness   *prefixFairness[K]
+	audit      *auditLog
+	evictions  *evictionHistory[K]
+	uniqueKeys *hyperLogLog
+	trace      *evictionTrace[K]
+	interned   *internTable
+	index      *secondaryIndex[K, V]
+
+	writeBehind *writeBehind[K, V]
+
+	// pending futures, see SetFuture
+	futures map[K]*future[V]
+	// background sweeper of expired entries, see StartJanitor
+	janitor *janitor
+	// event channels, see Subscribe
+	subscribers []chan Event[K, V]
+	// evicted entries waiting for the WithOnEvict hook, see unlockAndNotify
+	evicted []Entry[K, V]
+	// queue evictions even without hook, see SetWithEviction
+	capturingEvictions bool
+}
+
+// RemovalReason tells why an entry left the cache
+type RemovalReason int
+
+const (
+	// ReasonEvicted entries were dropped to make room for another one
+	ReasonEvicted RemovalReason = iota
+	// ReasonDeleted entries were explicitly deleted
+	ReasonDeleted
+	// ReasonExpired entries were found expired
+	ReasonExpired
+	// ReasonCascade entries were removed along with an entry they depend on, see SetWithDeps
+	ReasonCascade
+)
+
+func (reason RemovalReason) String() string {
+	switch reason {
+	case ReasonEvicted:
+		return "evicted"
+	case ReasonDeleted:
+		return "deleted"
+	case ReasonExpired:
+		return "expired"
+	case ReasonCascade:
+		return "cascade"
+	default:
+		return fmt.Sprintf("RemovalReason(%d)", int(reason))
+	}
 }
 
 // 	INTERNAL FUNCTIONS
 // 	WARNING: 		These function are not supposed to be used outside of this package,
 // 					they suppose that they are being used in a synchronized execution using mutexes
 
-// addToHead creates a new node and makes it the head of the DLL
-func (cache *LruCache) addToHead(key, value string) *cacheNode {
-	var node cacheNode
+// addToHead takes a node from the pool and makes it the head of the DLL
+func (cache *LruCache[K, V]) addToHead(key K, value V) *cacheNode[K, V] {
+	node := cache.nodePool.Get().(*cacheNode[K, V])
 	node.value = value
 	node.key = key
+	cache.linkAtHead(node)
+	return node
+}
 
+// linkAtHead inserts a node that is out of the DLL as its head
+func (cache *LruCache[K, V]) linkAtHead(node *cacheNode[K, V]) {
 	// handle empty cache case
 	if cache.head == nil {
-		node.next = &node
-		node.prev = &node
+		node.next = node
+		node.prev = node
 	} else {
 		// this code handles the single node case and multinode case correctly
 		// the single node case can be verified by tracking memory changes by hand for each instructions
 		node.next = cache.head
 		node.prev = cache.head.prev
 
-		cache.head.prev.next = &node
-		cache.head.prev = &node
+		cache.head.prev.next = node
+		cache.head.prev = node
 	}
-	cache.head = &node
-	return &node
+	cache.head = node
+}
+
+// releaseNode clears a node out of the DLL and the store and returns it to the pool.
+// Every field is reset: a stale link or entry must not leak into the next use of the node
+func (cache *LruCache[K, V]) releaseNode(node *cacheNode[K, V]) {
+	*node = cacheNode[K, V]{}
+	cache.nodePool.Put(node)
+}
+
+func newNodePool[K comparable, V any]() *sync.Pool {
+	return &sync.Pool{New: func() any { return new(cacheNode[K, V]) }}
 }
 
 // removeFromList removes a node from the DLL
-func (cache *LruCache) removeFromList(node *cacheNode) {
+func (cache *LruCache[K, V]) removeFromList(node *cacheNode[K, V]) {
 	// Handle single node case
 	if node.next == node {
 		cache.head = nil
@@ -77,98 +214,927 @@ func (cache *LruCache) removeFromList(node *cacheNode) {
 	node.next.prev = node.prev
 }
 
+// nodes returns the nodes of the DLL from head (MRU) to tail (LRU)
+func (cache *LruCache[K, V]) nodes() []*cacheNode[K, V] {
+	nodes := make([]*cacheNode[K, V], 0, len(cache.store))
+	if cache.head == nil {
+		return nodes
+	}
+	node := cache.head
+	for {
+		nodes = append(nodes, node)
+		node = node.next
+		if node == cache.head {
+			return nodes
+		}
+	}
+}
+
+// relink rebuilds the DLL from nodes, the first one becoming the head (MRU)
+func (cache *LruCache[K, V]) relink(nodes []*cacheNode[K, V]) {
+	if len(nodes) == 0 {
+		cache.head = nil
+		return
+	}
+	for i, node := range nodes {
+		// wrapping indexes close the circle
+		node.next = nodes[(i+1)%len(nodes)]
+		node.prev = nodes[(i+len(nodes)-1)%len(nodes)]
+	}
+	cache.head = nodes[0]
+}
+
+// discard removes a node from the DLL and the store, then cascades the removal to every
+// entry that depends on it. It returns how many dependents were removed along with it
+func (cache *LruCache[K, V]) discard(node *cacheNode[K, V], reason RemovalReason) (cascaded int) {
+	cache.unlink(node, reason)
+
+	pending := cache.takeDependents(node.key)
+	cache.releaseNode(node)
+	for len(pending) > 0 {
+		key := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		// already removed: this is what stops dependency cycles
+		dependent, ok := cache.store[key]
+		if !ok {
+			continue
+		}
+		cache.unlink(dependent, ReasonCascade)
+		cascaded++
+		pending = append(pending, cache.takeDependents(key)...)
+		cache.releaseNode(dependent)
+	}
+	return cascaded
+}
+
+// unlink removes a single node from the DLL, the store and the dependency index
+func (cache *LruCache[K, V]) unlink(node *cacheNode[K, V], reason RemovalReason) {
+	cache.removeFromList(node)
+	delete(cache.store, node.key)
+	cache.currentBytes -= entrySize(node.key, node.value)
+	cache.clearDependencies(node.key)
+	cache.trackPrefix(node.key, -1)
+	cache.trackPriority(node.priority, -1)
+	cache.queueEviction(node, reason)
+	cache.releaseValue(node.value)
+	cache.unindexNode(node)
+	cache.auditRemoval(node.key, reason)
+	cache.recordEviction(node.key, reason)
+	cache.recordTrace(node.key, reason)
+	cache.countEviction(reason)
+	cache.publishRemoval(node, reason)
+}
+
 // Public Functions
 // 	______________________
 
 // Get retrieves a value from the cache by its key.
 // It behaves just like map access eg: value,ok:=m[key]
-func (cache *LruCache) Get(key string) (value string, ok bool) {
+// On a miss the WithOnMiss hook is called, after the lock is released
+// If a future is pending for key (see SetFuture) Get waits for it, use GetCtx to bound the wait
+func (cache *LruCache[K, V]) Get(key K) (value V, ok bool) {
+	value, ok, _ = cache.GetCtx(context.Background(), key)
+	return value, ok
+}
+
+// Peek is Get without the recency update: the entry keeps its position in the eviction order.
+// An expired entry is still removed on the spot.
+// Unlike Get it doesn't call the WithOnMiss hook, nor waits for a pending future
+func (cache *LruCache[K, V]) Peek(key K) (value V, ok bool) {
 	// protect DS
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	node, ok := cache.store[key]
+	if !ok {
+		return value, false
+	}
+	if cache.expired(node) {
+		cache.discard(node, ReasonExpired)
+		return value, false
+	}
+	return node.value, true
+}
+
+// Contains reports whether key is cached, with no side effect: no recency update, no hit or miss
+// counted, and an expired entry is reported missing but left for the next access to remove
+func (cache *LruCache[K, V]) Contains(key K) bool {
+	// protect DS, readers only
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	node, ok := cache.store[key]
+	return ok && !cache.expired(node)
+}
+
+// get is the unsynchronized body of Get
+func (cache *LruCache[K, V]) get(key K) (value V, ok bool) {
+	cache.countOp(opGet)
 
 	// Get the node
 	node, ok := cache.store[key]
 
+	// an expired node is removed on the spot
+	if ok && cache.expired(node) {
+		cache.discard(node, ReasonExpired)
+		ok = false
+	}
+
 	// get the value
+	cache.countGet(ok)
 	if !ok {
-		return "", ok
+		return value, ok
+	}
+	cache.hit(node)
+	return node.value, ok
+}
+
+// hit records an access to node: it is touched and promoted, unless batching delays the promotion
+// or the policy doesn't reorder on access
+func (cache *LruCache[K, V]) hit(node *cacheNode[K, V]) {
+	cache.touch(node)
+	switch cache.policy {
+	case PolicyLFU:
+		node.frequency++
+	case PolicyFIFO:
+		return
+	}
+
+	// already the MRU, no relinking needed
+	if node == cache.head {
+		return
+	}
+
+	// batched promotions wait for enough hits
+	if cache.promotionBatch > 1 {
+		node.pendingHits++
+		if int(node.pendingHits) < cache.promotionBatch {
+			return
+		}
+	}
+
+	cache.promote(node)
+}
+
+// sharedHit records a hit on node under the read lock, if the hit needs no write but to counters,
+// and reports whether it did. The caller records it under the write lock otherwise
+func (cache *LruCache[K, V]) sharedHit(node *cacheNode[K, V]) bool {
+	// touching, LFU counts and the ops rate are plain writes, debug checks run under the write lock
+	if cache.maxIdle > 0 || cache.policy == PolicyLFU || cache.opsRate != nil || cache.debugChecks {
+		return false
+	}
+	if cache.policy == PolicyFIFO || node == cache.head {
+		return true
+	}
+	// the Get reaching the batch promotes, its hit is counted again under the write lock
+	// (the count then exceeds the batch, which promotes all the same)
+	if cache.promotionBatch > 1 {
+		return int(atomic.AddInt32(&node.pendingHits, 1)) < cache.promotionBatch
+	}
+	return false
+}
+
+// promote moves node to the head (MRU) by relinking it: the store keeps pointing to the same node
+func (cache *LruCache[K, V]) promote(node *cacheNode[K, V]) {
+	if node == cache.head {
+		return
 	}
 
-	// update the internals
 	cache.removeFromList(node)
-	newNode := cache.addToHead(key, node.value)
-	cache.store[key] = newNode
+	cache.linkAtHead(node)
+	node.pendingHits = 0
+}
 
-	return node.value, ok
+// PromoteAll marks keys as recently used together: each present key is moved to MRU in the order
+// of keys, the last one ending up the head, all under one lock. Missing keys are skipped.
+// It returns how many keys were present
+func (cache *LruCache[K, V]) PromoteAll(keys []K) (promoted int) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	for _, key := range keys {
+		node, ok := cache.store[key]
+		if !ok {
+			continue
+		}
+		if cache.expired(node) {
+			cache.discard(node, ReasonExpired)
+			continue
+		}
+		cache.touch(node)
+		cache.promote(node)
+		promoted++
+	}
+	return promoted
 }
 
 // Set adds or updates a key-value pair in the cache.
 // Newly Set/Updated Elements are Added/Moved to the head
-func (cache *LruCache) Set(key, value string) (updated bool) {
+// An entry larger than the byte budget of a NewSizeCache is not stored, see TrySet
+// Setting a key whose entry has expired is not an update: the dead entry is removed first
+// and the new one is inserted from scratch
+func (cache *LruCache[K, V]) Set(key K, value V) (updated bool) {
 	// protect DS
 	cache.mutex.Lock()
-	defer cache.mutex.Unlock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	return cache.set(key, value)
+}
+
+// set is the unsynchronized body of Set
+func (cache *LruCache[K, V]) set(key K, value V) (updated bool) {
+	// too large to ever fit, an existing entry of key is kept
+	if !cache.fits(key, value) {
+		return false
+	}
+
+	cache.countOp(opSet)
+	cache.trackUniqueKey(key)
 
 	// check if this an update
+	var seq uint64
+	var priority int
+	var frequency uint64
 	existing, ok := cache.store[key]
+
+	// an expired entry is dead already, setting its key is a fresh insertion
+	if ok && cache.expired(existing) {
+		cache.discard(existing, ReasonExpired)
+		ok = false
+	}
+
 	if ok {
 		cache.removeFromList(existing)
 		delete(cache.store, existing.key)
+		cache.currentBytes -= entrySize(existing.key, existing.value)
+		cache.releaseValue(existing.value)
+		cache.unindexNode(existing)
+		cache.trackPriority(existing.priority, -1)
+		priority = existing.priority
+		frequency = existing.frequency
 		updated = true
-	} else if len(cache.store) == cache.capacity {
-		tail := cache.head.prev
-		cache.removeFromList(tail)
-		delete(cache.store, tail.key)
+		if !cache.refreshSeqOnUpdate {
+			seq = existing.seq
+		}
+		cache.releaseNode(existing)
+	} else if cache.evictionSuspended {
+		// the cache is allowed to grow until ResumeEviction
+	} else if victim := cache.fairnessVictim(key); victim != nil {
+		// the prefix of key is at its limit, it pays for its own insertion
+		cache.discard(victim, ReasonEvicted)
+	}
+
+	// a loop rather than a single eviction: should the store ever grow past the capacity,
+	// the next insertion restores the invariant instead of keeping the excess forever
+	for !updated && !cache.evictionSuspended && len(cache.store) >= cache.capacity {
+		cache.discard(cache.evictionVictim(), ReasonEvicted)
+	}
+	// the byte budget applies to updates as well: a larger value may not fit anymore
+	size := entrySize(key, value)
+	for !cache.evictionSuspended && cache.head != nil && cache.overBudget(size) {
+		cache.discard(cache.evictionVictim(), ReasonEvicted)
+	}
+
+	// new entries, and refreshed updates, get the next insertion sequence number
+	if seq == 0 {
+		cache.seq++
+		seq = cache.seq
 	}
 
 	// add new node
-	node := cache.addToHead(key, value)
+	node := cache.addToHead(key, cache.internValue(value))
+	node.seq = seq
+	node.priority = priority
+	node.generation = cache.generation
+	if cache.policy == PolicyLFU {
+		node.frequency = frequency + 1
+	}
+	cache.trackPriority(priority, 1)
+	cache.currentBytes += size
+	cache.touch(node)
 	cache.store[key] = node
+	cache.indexNode(node)
+	cache.markDirty(key, node.value)
+	if len(cache.subscribers) > 0 {
+		cache.publish(EventSet, key, node.value)
+	}
+	if !updated {
+		cache.trackPrefix(key, 1)
+	}
 	return
 }
 
+// Update replaces the value of key with fn(old value) and makes the entry the MRU, atomically: no
+// other operation runs between the read and the write. It returns false, without calling fn, if key
+// is missing or expired. Unlike a Set the entry keeps its TTL. A result too large for the byte
+// budget of a NewSizeCache is dropped and the entry left as is, Update then returns false.
+// fn runs under the lock, it must not call the cache
+func (cache *LruCache[K, V]) Update(key K, fn func(old V) V) (updated bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	node, ok := cache.store[key]
+	if ok && cache.expired(node) {
+		cache.discard(node, ReasonExpired)
+		ok = false
+	}
+	if !ok {
+		return false
+	}
+
+	value := fn(node.value)
+	if !cache.fits(key, value) {
+		return false
+	}
+	// set releases the node, keep its deadline
+	expiresAt := node.expiresAt
+	cache.set(key, value)
+	cache.store[key].expiresAt = expiresAt
+	return true
+}
+
 // Delete removes the item associated to key, it returns true if element exists, false otherwise
-func (cache *LruCache) Delete(key string) (ok bool) {
+// An expired entry doesn't exist: it is removed as expired, and false returned.
+// Entries depending on key (see SetWithDeps) are removed as well
+func (cache *LruCache[K, V]) Delete(key K) (ok bool) {
+	ok, _ = cache.DeleteWithDeps(key)
+	return ok
+}
+
+// GetAndDelete removes key and returns the value it held, in a single step: of concurrent callers
+// only one gets the value. Like Delete it also removes the entries depending on key.
+// A missing or expired key returns ok false, an expired entry is removed as expired
+func (cache *LruCache[K, V]) GetAndDelete(key K) (value V, ok bool) {
 	// protect DS
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
 
-	// check if it exists
-	existing, ok := cache.store[key]
+	cache.countOp(opDelete)
+
+	node, ok := cache.store[key]
+	if ok && cache.expired(node) {
+		cache.discard(node, ReasonExpired)
+		ok = false
+	}
+	cache.countGet(ok)
 	if !ok {
+		return value, false
+	}
+
+	value = node.value
+	cache.discard(node, ReasonDeleted)
+	return value, true
+}
+
+// CompareAndDelete removes key only if its current value equals expected.
+// It returns true if the entry was removed, false if it is missing or holds another value.
+// An expired entry is missing: it is removed as expired whatever its value, and false returned.
+// Values are compared with ==, methods can't require V to be comparable hence the function
+func CompareAndDelete[K, V comparable](cache *LruCache[K, V], key K, expected V) (deleted bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	cache.countOp(opDelete)
+
+	existing, ok := cache.store[key]
+	if ok && cache.expired(existing) {
+		cache.discard(existing, ReasonExpired)
+		return false
+	}
+	if !ok || existing.value != expected {
 		return false
 	}
 
-	cache.removeFromList(existing)
-	delete(cache.store, existing.key)
+	cache.discard(existing, ReasonDeleted)
 	return true
 }
 
+// Neighbors returns the entries right before (more recently used) and right after (less recently used)
+// key in the LRU order, nil at the ends. Expired entries are skipped, as if they were gone.
+// The order is left untouched, ok is false if key is missing
+func (cache *LruCache[K, V]) Neighbors(key K) (prev, next *Entry[K, V], ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	node, ok := cache.store[key]
+	if !ok || cache.expired(node) {
+		return nil, nil, false
+	}
+
+	// the list is circular: the head has no previous entry and the tail no next one
+	for before := node; before != cache.head; {
+		before = before.prev
+		if !cache.expired(before) {
+			prev = &Entry[K, V]{Key: before.key, Value: before.value}
+			break
+		}
+	}
+	for after := node.next; after != cache.head; after = after.next {
+		if !cache.expired(after) {
+			next = &Entry[K, V]{Key: after.key, Value: after.value}
+			break
+		}
+	}
+	return prev, next, true
+}
+
+// Reorder sets the LRU order of the whole cache: after it, entries are ordered from MRU to LRU
+// so that a comes before b when less(a, b). Entries that compare equal keep their relative order.
+// less runs under the lock, it must not call the cache
+func (cache *LruCache[K, V]) Reorder(less func(a, b Entry[K, V]) bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	nodes := cache.nodes()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return less(
+			Entry[K, V]{Key: nodes[i].key, Value: nodes[i].value},
+			Entry[K, V]{Key: nodes[j].key, Value: nodes[j].value},
+		)
+	})
+	cache.relink(nodes)
+}
+
+// GetOldest returns the entry the next eviction would remove, without changing the order: the
+// least recently used one, within the lowest priority tier and according to the policy.
+// Expired entries found on the way are removed. ok is false on an empty cache
+func (cache *LruCache[K, V]) GetOldest() (key K, value V, ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	node := cache.oldest()
+	if node == nil {
+		return key, value, false
+	}
+	return node.key, node.value, true
+}
+
+// RemoveOldest evicts the entry GetOldest returns and returns it, along with the entries depending
+// on it. It is a capacity eviction for the WithOnEvict hook and the statistics
+func (cache *LruCache[K, V]) RemoveOldest() (key K, value V, ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	node := cache.oldest()
+	if node == nil {
+		return key, value, false
+	}
+	key, value = node.key, node.value
+	cache.discard(node, ReasonEvicted)
+	return key, value, true
+}
+
+// oldest returns the next eviction victim still alive, nil if there is none
+func (cache *LruCache[K, V]) oldest() *cacheNode[K, V] {
+	for cache.head != nil {
+		node := cache.evictionVictim()
+		if !cache.expired(node) {
+			return node
+		}
+		cache.discard(node, ReasonExpired)
+	}
+	return nil
+}
+
+// GetRandom returns a random entry without promoting it, ok is false on an empty cache.
+// It relies on the randomized map iteration order, which is cheap but not perfectly uniform
+func (cache *LruCache[K, V]) GetRandom() (key K, value V, ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, node := range cache.store {
+		if cache.expired(node) {
+			continue
+		}
+		return node.key, node.value, true
+	}
+	return key, value, false
+}
+
+// SuspendEviction lets Set insert past the capacity until ResumeEviction is called,
+// for instance to load more entries than fit and only keep the most recent ones.
+// While suspended nothing bounds the memory used by the cache: keep the suspension short
+func (cache *LruCache[K, V]) SuspendEviction() {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.evictionSuspended = true
+}
+
+// ResumeEviction ends a SuspendEviction and evicts LRU entries until the cache fits its capacity,
+// or its byte budget.
+// It returns how many entries were evicted
+func (cache *LruCache[K, V]) ResumeEviction() (evicted int) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+
+	cache.evictionSuspended = false
+	for cache.head != nil && cache.overBudget(0) {
+		evicted += 1 + cache.discard(cache.evictionVictim(), ReasonEvicted)
+	}
+	return evicted
+}
+
+// Keys returns the keys ordered from the most recently used (head) to the least recently used (tail).
+// Expired entries are left out. An empty cache returns an empty, non-nil slice
+func (cache *LruCache[K, V]) Keys() []K {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entries := cache.entries()
+	keys := make([]K, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.Key
+	}
+	return keys
+}
+
+// Entries is Keys with the values
+func (cache *LruCache[K, V]) Entries() []Entry[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.entries()
+}
+
+// ForEach calls fn on every entry from MRU to LRU, until fn returns false. The entries are
+// snapshotted under the lock and fn runs after it is released: fn may use the cache, and changes
+// made meanwhile are not reflected
+func (cache *LruCache[K, V]) ForEach(fn func(key K, value V) bool) {
+	for _, entry := range cache.Entries() {
+		if !fn(entry.Key, entry.Value) {
+			return
+		}
+	}
+}
+
+// entries copies out the live entries from MRU to LRU
+func (cache *LruCache[K, V]) entries() []Entry[K, V] {
+	entries := make([]Entry[K, V], 0, len(cache.store))
+	for _, node := range cache.nodes() {
+		if cache.expired(node) {
+			continue
+		}
+		entries = append(entries, Entry[K, V]{Key: node.key, Value: node.value})
+	}
+	return entries
+}
+
+// Clear removes every entry at once. It is not a series of removals: the WithOnEvict hook is not
+// called, and nothing is counted or recorded as evicted. Counters and histories are kept, as are
+// the pending futures and the entries waiting for a write-behind flush
+func (cache *LruCache[K, V]) Clear() {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	cache.clearEntries()
+}
+
+// clearEntries drops the entries and everything derived from them.
+// The structures are replaced rather than emptied: a detached copy of the cache may still use them
+func (cache *LruCache[K, V]) clearEntries() {
+	if len(cache.subscribers) > 0 && cache.head != nil {
+		var key K
+		var value V
+		cache.publish(EventClear, key, value)
+	}
+	cache.head = nil
+	cache.store = make(map[K]*cacheNode[K, V])
+	cache.currentBytes = 0
+	cache.dependents = make(map[K]map[K]struct{})
+	cache.dependsOn = make(map[K][]K)
+	cache.priorities = nil
+	if cache.fairness != nil {
+		cache.fairness = &prefixFairness[K]{
+			prefixOf: cache.fairness.prefixOf,
+			share:    cache.fairness.share,
+			limit:    cache.fairness.limit,
+			counts:   make(map[string]int),
+		}
+	}
+	if cache.interned != nil {
+		cache.interned = newInternTable()
+	}
+	if cache.index != nil {
+		cache.index = &secondaryIndex[K, V]{extract: cache.index.extract, keys: make(map[string]K)}
+	}
+}
+
+// Resize changes the capacity of the cache. Shrinking below Len evicts entries the way Set does,
+// LRU first, until the cache fits, and returns how many were removed. Growing evicts nothing.
+// A newCapacity <= 0 is rejected with an error and the capacity is left unchanged.
+// While eviction is suspended nothing is evicted, ResumeEviction enforces the new capacity
+func (cache *LruCache[K, V]) Resize(newCapacity int) (evicted int, err error) {
+	if newCapacity <= 0 {
+		return 0, fmt.Errorf("capacity must be greater than 0, got %d", newCapacity)
+	}
+
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	cache.capacity = newCapacity
+	if cache.fairness != nil {
+		cache.fairness.resize(newCapacity)
+	}
+	for !cache.evictionSuspended && cache.overBudget(0) {
+		evicted += 1 + cache.discard(cache.evictionVictim(), ReasonEvicted)
+	}
+	return evicted, nil
+}
+
+// KeyLengthStats returns the shortest, longest and mean key length in bytes over the current keys.
+// An empty cache reports zeros for all three. Only string keys have a length
+func KeyLengthStats[V any](cache *LruCache[string, V]) (min, max int, mean float64) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	count, total := 0, 0
+	for key, node := range cache.store {
+		if cache.expired(node) {
+			continue
+		}
+		if count == 0 || len(key) < min {
+			min = len(key)
+		}
+		if len(key) > max {
+			max = len(key)
+		}
+		count++
+		total += len(key)
+	}
+
+	if count == 0 {
+		return 0, 0, 0
+	}
+	return min, max, float64(total) / float64(count)
+}
+
+// Close releases the background resources of the cache: it stops the janitor, closes the event
+// subscriptions, flushes the pending write-behind entries and the audit log, returning the first
+// error met. The cache remains usable, but removals are no longer audited and writes are no
+// longer flushed in the background.
+// Calling Close more than once is safe
+func (cache *LruCache[K, V]) Close() error {
+	cache.StopJanitor()
+	cache.unsubscribeAll()
+
+	var err error
+	if cache.writeBehind != nil {
+		err = cache.stopWriteBehind()
+	}
+	if cache.audit != nil {
+		if auditErr := cache.audit.close(); err == nil {
+			err = auditErr
+		}
+	}
+	return err
+}
+
+// IsConcurrencySafe reports whether the cache can be shared between goroutines,
+// that is false only for caches created by NewUnsafeCache
+func (cache *LruCache[K, V]) IsConcurrencySafe() bool {
+	mutex := cache.mutex
+	if tracker, ok := mutex.(*lockHoldTracker); ok {
+		mutex = tracker.rwLocker
+	}
+	_, unsafe := mutex.(noLock)
+	return !unsafe
+}
+
 // Getter for cache.capacity
-func (cache *LruCache) Capacity() int {
+func (cache *LruCache[K, V]) Capacity() int {
+	// protect DS, the capacity changes with Resize
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
 	return cache.capacity
 }
 
 // Returns current cache size
-func (cache *LruCache) Len() int {
+func (cache *LruCache[K, V]) Len() int {
+	// protect DS, the janitor removes entries in the background
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
 	return len(cache.store)
 }
 
-// NewCache creates and returns a new LRU cache with the specified capacity.
-// Returns an error if capacity is less than or equal to zero.
-func NewCache(capacity int) (*LruCache, error) {
+// FreeSlots returns how many entries can be inserted before eviction starts, 0 once the cache is full
+// (or past its capacity while eviction is suspended).
+// A NewSizeCache bounds bytes rather than entries, FreeSlots returns -1 for it: see FreeBytes
+func (cache *LruCache[K, V]) FreeSlots() int {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.maxBytes > 0 {
+		return -1
+	}
+	return max(cache.capacity-len(cache.store), 0)
+}
+
+// NewCache creates and returns a new LRU cache with the specified capacity,
+// holding values of type V under keys of type K.
+// Returns an error if capacity is less than or equal to zero, or if an Option was given
+// callbacks for other key or value types.
+// Optional features are enabled by passing Options
+func NewCache[K comparable, V any](capacity int, opts ...Option) (*LruCache[K, V], error) {
+	return newCache[K, V](capacity, &sync.RWMutex{}, opts)
+}
+
+// NewUnsafeCache is NewCache without any locking, for caches used by a single goroutine.
+// WARNING: the cache is not safe for concurrent use, sharing it between goroutines corrupts the DLL.
+// Only the locking differs, it behaves exactly like a cache returned by NewCache otherwise,
+// except for the features running a goroutine of their own: WithWriteBehind, StartJanitor,
+// ReplicaView and SetFuture fail on an unsafe cache
+func NewUnsafeCache[K comparable, V any](capacity int, opts ...Option) (*LruCache[K, V], error) {
+	return newCache[K, V](capacity, noLock{}, opts)
+}
+
+// StringCache is the cache of string values under string keys,
+// the only kind of cache before keys and values were made generic
+type StringCache = LruCache[string, string]
+
+// NewStringCache is NewCache for a StringCache
+func NewStringCache(capacity int, opts ...Option) (*StringCache, error) {
+	return NewCache[string, string](capacity, opts...)
+}
+
+// noLock is the mutex of caches created by NewUnsafeCache
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+// rwLocker is the mutex of a cache: a sync.RWMutex, or a noLock for unsafe caches
+type rwLocker interface {
+	sync.Locker
+	RLock()
+	RUnlock()
+}
+
+// newMutex returns an unlocked mutex of the same kind as the one of the cache,
+// for a copy of the cache that must not share it
+func (cache *LruCache[K, V]) newMutex() rwLocker {
+	var mutex rwLocker = noLock{}
+	if cache.IsConcurrencySafe() {
+		mutex = &sync.RWMutex{}
+	}
+	if tracker, ok := cache.mutex.(*lockHoldTracker); ok {
+		return &lockHoldTracker{rwLocker: mutex, now: tracker.now}
+	}
+	return mutex
+}
+
+// typed returns the callback fn given to an Option as a T, fn being nil or a T.
+// Options don't know the types of the cache, a callback for other types is only caught here
+func typed[T any](option string, fn any) (T, error) {
+	var callback T
+	if fn == nil {
+		return callback, nil
+	}
+	callback, ok := fn.(T)
+	if !ok {
+		return callback, fmt.Errorf("%s expects a %T, got a %T", option, callback, fn)
+	}
+	return callback, nil
+}
+
+func newCache[K comparable, V any](capacity int, mutex rwLocker, opts []Option) (*LruCache[K, V], error) {
 	if capacity <= 0 {
 		return nil, fmt.Errorf("capacity must be greater than 0")
 	}
 
-	var mutex sync.Mutex
-	store := make(map[string]*cacheNode)
-	var cache LruCache = LruCache{
-		mutex:    &mutex,
+	config := defaultOptions()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.prefixFn != nil && (config.maxPrefixShare <= 0 || config.maxPrefixShare > 1) {
+		return nil, fmt.Errorf("maxSharePerPrefix must be in (0, 1], got %v", config.maxPrefixShare)
+	}
+	if config.writeBehindFlush != nil && (config.writeBehindInterval <= 0 || config.writeBehindBatch <= 0) {
+		return nil, fmt.Errorf("write-behind interval and batch size must be greater than 0")
+	}
+	// the worker shares the cache with the caller, which an unsafe cache can't survive
+	if _, unsafe := mutex.(noLock); unsafe && config.writeBehindFlush != nil {
+		return nil, fmt.Errorf("write-behind requires a concurrency safe cache")
+	}
+	if config.policy < PolicyLRU || config.policy > PolicyFIFO {
+		return nil, fmt.Errorf("unknown eviction policy %v", config.policy)
+	}
+	if config.lfuTieBreak < LFUTieLRU || config.lfuTieBreak > LFUTieFIFO {
+		return nil, fmt.Errorf("unknown LFU tie-break %d", config.lfuTieBreak)
+	}
+	if config.promotionBatch <= 0 {
+		return nil, fmt.Errorf("promotion batch must be greater than 0, got %d", config.promotionBatch)
+	}
+	if _, stringValues := any(*new(V)).(string); config.internValues && !stringValues {
+		return nil, fmt.Errorf("string interning requires string values")
+	}
+
+	onMiss, err := typed[func(key K)]("WithOnMiss", config.onMiss)
+	if err != nil {
+		return nil, err
+	}
+	onEvict, err := typed[func(key K, value V)]("WithOnEvict", config.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	prefixFn, err := typed[func(key K) string]("WithPrefixFairness", config.prefixFn)
+	if err != nil {
+		return nil, err
+	}
+	indexExtract, err := typed[func(value V) string]("WithSecondaryIndex", config.indexExtract)
+	if err != nil {
+		return nil, err
+	}
+	writeBehindFlush, err := typed[func(batch map[K]V) error]("WithWriteBehind", config.writeBehindFlush)
+	if err != nil {
+		return nil, err
+	}
+
+	store := make(map[K]*cacheNode[K, V])
+	var cache LruCache[K, V] = LruCache[K, V]{
+		mutex:    mutex,
 		store:    store,
+		nodePool: newNodePool[K, V](),
 		head:     nil,
 		capacity: capacity,
+
+		dependents: make(map[K]map[K]struct{}),
+		dependsOn:  make(map[K][]K),
+
+		refreshSeqOnUpdate: config.refreshSeqOnUpdate,
+		debugChecks:        config.debugChecks,
+		promotionBatch:     config.promotionBatch,
+		policy:             config.policy,
+		lfuTieBreak:        config.lfuTieBreak,
+
+		onMiss:  onMiss,
+		onEvict: onEvict,
+		now:     config.now,
+		maxIdle: config.maxIdle,
+	}
+	if config.lockHoldTracking {
+		cache.mutex = &lockHoldTracker{rwLocker: mutex, now: config.now}
+	}
+	if config.trackOpsRate {
+		cache.opsRate = &opsRate{}
+	}
+	if prefixFn != nil {
+		cache.fairness = newPrefixFairness(prefixFn, config.maxPrefixShare, capacity)
+	}
+	if config.sharedAudit != nil {
+		cache.audit = config.sharedAudit
+	} else if config.auditLog != nil {
+		cache.audit = newAuditLog(config.auditLog, auditQueueSize)
+	}
+	if config.evictionHistory {
+		cache.evictions = &evictionHistory[K]{}
+	}
+	if config.evictionRecorder {
+		cache.trace = &evictionTrace[K]{}
+	}
+	if config.uniqueKeyEstimation {
+		cache.uniqueKeys = newHyperLogLog()
+	}
+	if config.internValues {
+		cache.interned = newInternTable()
+	}
+	if indexExtract != nil {
+		cache.index = &secondaryIndex[K, V]{extract: indexExtract, keys: make(map[string]K)}
+	}
+	if writeBehindFlush != nil {
+		cache.writeBehind = newWriteBehind(writeBehindFlush, config.writeBehindBatch)
+		go cache.runWriteBehind(config.writeBehindInterval)
 	}
 	return &cache, nil
 }
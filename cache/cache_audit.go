@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The audit log records every removal as a line "<timestamp> <quoted key> <reason>", keys being
+// formatted with fmt.Sprint.
+// Removals happen under the cache mutex, so they are only queued there: a background goroutine
+// drains the queue into a buffered writer, flushed periodically and when the cache is closed.
+// The queue is bounded and never blocks, a record that doesn't fit is dropped and counted
+
+const (
+	auditQueueSize     = 1024
+	auditFlushInterval = time.Second
+)
+
+type auditRecord struct {
+	at     time.Time
+	key    string
+	reason RemovalReason
+}
+
+type auditLog struct {
+	// guards closed against concurrent records, so nothing is sent on a closed queue
+	mutex   sync.RWMutex
+	closed  bool
+	queue   chan auditRecord
+	dropped atomic.Uint64
+
+	// closed by the writer goroutine once the queue is drained, err is readable afterward
+	done chan struct{}
+	err  error
+}
+
+// WithAuditLog writes a line to w for every entry removed from the cache (eviction, deletion,
+// expiry or cascade). Writes happen in the background, Close flushes what is left
+func WithAuditLog(w io.Writer) Option {
+	return func(o *options) {
+		o.auditLog = w
+	}
+}
+
+// withSharedAudit makes the cache record its removals in log, which it doesn't own alone: the
+// shards of a ShardedCache write to a single writer through a single goroutine
+func withSharedAudit(log *auditLog) Option {
+	return func(o *options) {
+		o.auditLog = nil
+		o.sharedAudit = log
+	}
+}
+
+func newAuditLog(w io.Writer, queueSize int) *auditLog {
+	log := &auditLog{
+		queue: make(chan auditRecord, queueSize),
+		done:  make(chan struct{}),
+	}
+	go log.run(w)
+	return log
+}
+
+// run writes queued records until the queue is closed
+func (log *auditLog) run(w io.Writer) {
+	defer close(log.done)
+
+	buffer := bufio.NewWriter(w)
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case record, ok := <-log.queue:
+			if !ok {
+				log.keepErr(buffer.Flush())
+				return
+			}
+			_, err := fmt.Fprintf(buffer, "%s %q %s\n", record.at.Format(time.RFC3339Nano), record.key, record.reason)
+			log.keepErr(err)
+		case <-ticker.C:
+			log.keepErr(buffer.Flush())
+		}
+	}
+}
+
+// keepErr remembers the first write error
+func (log *auditLog) keepErr(err error) {
+	if log.err == nil {
+		log.err = err
+	}
+}
+
+// record queues a record without ever blocking
+func (log *auditLog) record(record auditRecord) {
+	log.mutex.RLock()
+	defer log.mutex.RUnlock()
+
+	if log.closed {
+		log.dropped.Add(1)
+		return
+	}
+	select {
+	case log.queue <- record:
+	default:
+		log.dropped.Add(1)
+	}
+}
+
+// close stops accepting records and waits until the queued ones are written
+func (log *auditLog) close() error {
+	log.mutex.Lock()
+	if !log.closed {
+		log.closed = true
+		close(log.queue)
+	}
+	log.mutex.Unlock()
+
+	<-log.done
+	return log.err
+}
+
+// auditRemoval records the removal of key when the audit log is enabled
+func (cache *LruCache[K, V]) auditRemoval(key K, reason RemovalReason) {
+	if cache.audit != nil {
+		cache.audit.record(auditRecord{at: cache.now(), key: fmt.Sprint(key), reason: reason})
+	}
+}
+
+// DroppedAuditRecords returns how many removals were left out of the audit log
+// because its queue was full or the cache was closed
+func (cache *LruCache[K, V]) DroppedAuditRecords() uint64 {
+	if cache.audit == nil {
+		return 0
+	}
+	return cache.audit.dropped.Load()
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	clock := newFakeClock()
+	var out bytes.Buffer
+	cache, _ := NewStringCache(2, WithClock(clock.Now), WithAuditLog(&out))
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3") // evicts key1
+	clock.Advance(time.Second)
+	cache.Delete("key2")
+	cache.SetWithDeps("key4", "value4", []string{"key3"})
+	cache.Delete("key3") // takes key4 along
+	cache.Delete("missing")
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	start := clock.Now().Add(-time.Second).Format(time.RFC3339Nano)
+	end := clock.Now().Format(time.RFC3339Nano)
+	want := []string{
+		fmt.Sprintf(`%s "key1" evicted`, start),
+		fmt.Sprintf(`%s "key2" deleted`, end),
+		fmt.Sprintf(`%s "key3" deleted`, end),
+		fmt.Sprintf(`%s "key4" cascade`, end),
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("audit log =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if dropped := cache.DroppedAuditRecords(); dropped != 0 {
+		t.Errorf("DroppedAuditRecords() = %d, want 0", dropped)
+	}
+
+	// the cache stays usable once closed, further removals are dropped
+	cache.Delete("key4")
+	cache.Set("key5", "value5")
+	cache.Delete("key5")
+	if dropped := cache.DroppedAuditRecords(); dropped != 1 {
+		t.Errorf("DroppedAuditRecords() after Close = %d, want 1", dropped)
+	}
+	if err := cache.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+}
+
+// blockingWriter blocks every Write until released, signaling when a Write starts
+type blockingWriter struct {
+	entered chan struct{}
+	release chan struct{}
+	out     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.release
+	return w.out.Write(p)
+}
+
+func TestAuditLogDropsWhenFull(t *testing.T) {
+	writer := &blockingWriter{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	cache, _ := NewStringCache(10)
+	cache.audit = newAuditLog(writer, 2)
+
+	// a key larger than the write buffer forces a Write, which blocks the writer goroutine
+	bigKey := strings.Repeat("k", 5000)
+	cache.Set(bigKey, "value")
+	cache.Delete(bigKey)
+	<-writer.entered
+
+	// 2 records fit in the queue, the 3 others are dropped
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("key%d", i)
+		cache.Set(key, "value")
+		cache.Delete(key)
+	}
+	if dropped := cache.DroppedAuditRecords(); dropped != 3 {
+		t.Errorf("DroppedAuditRecords() = %d, want 3", dropped)
+	}
+
+	close(writer.release)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if lines := strings.Count(writer.out.String(), "\n"); lines != 3 {
+		t.Errorf("audit log has %d lines, want 3", lines)
+	}
+}
+
+func TestCloseWithoutBackgroundWork(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	if err := cache.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+// MGet is Get for several keys under a single lock. It returns the entries found, each promoted
+// like Get does, in the order of keys: the last key found ends up the MRU. Missing keys are left
+// out of the map and reported to the WithOnMiss hook after the lock is released.
+// Unlike Get it doesn't wait for pending futures, their keys are missing
+func (cache *LruCache[K, V]) MGet(keys []K) map[K]V {
+	found := make(map[K]V, len(keys))
+	var missing []K
+
+	// a closure for the defers: a failed debug check must not leave the lock held
+	func() {
+		// protect DS
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		defer cache.debugCheck()
+
+		for _, key := range keys {
+			if value, ok := cache.get(key); ok {
+				found[key] = value
+			} else {
+				missing = append(missing, key)
+			}
+		}
+	}()
+
+	// the hook may use the cache, it must run unlocked
+	if cache.onMiss != nil {
+		for _, key := range missing {
+			cache.onMiss(key)
+		}
+	}
+	return found
+}
+
+// MSet is Set for every pair of items under a single lock, evicting as it goes. The pairs are set in
+// map iteration order, so when there are more new keys than free slots which ones survive is
+// unspecified, only that the cache ends up full
+func (cache *LruCache[K, V]) MSet(items map[K]V) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	for key, value := range items {
+		cache.set(key, value)
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMGet(t *testing.T) {
+	var missed []string
+	cache, _ := NewStringCache(5, WithOnMiss(func(key string) { missed = append(missed, key) }))
+	for i := 1; i <= 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	got := cache.MGet([]string{"key1", "missing", "key3"})
+	want := map[string]string{"key1": "value1", "key3": "value3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MGet() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(missed, []string{"missing"}) {
+		t.Errorf("misses reported = %v, want [missing]", missed)
+	}
+
+	// the keys found are promoted in order
+	wantKeys := []string{"key3", "key1", "key5", "key4", "key2"}
+	if keys := cache.Keys(); !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("Keys() after MGet = %v, want %v", keys, wantKeys)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 2 hits and 1 miss", stats)
+	}
+}
+
+func TestMSet(t *testing.T) {
+	cache, _ := NewStringCache(3)
+
+	items := make(map[string]string)
+	for i := 1; i <= 10; i++ {
+		items[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	cache.MSet(items)
+
+	if cache.Len() != 3 {
+		t.Errorf("Len() after MSet = %d, want 3", cache.Len())
+	}
+	for _, entry := range cache.Entries() {
+		if entry.Value != items[entry.Key] {
+			t.Errorf("entry %s = %s, want %s", entry.Key, entry.Value, items[entry.Key])
+		}
+	}
+	if stats := cache.Stats(); stats.Evictions != 7 {
+		t.Errorf("Stats().Evictions = %d, want 7", stats.Evictions)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestMGetDebugCheckReleasesLock(t *testing.T) {
+	cache, _ := NewStringCache(5, WithDebugIntegrityChecks())
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	delete(cache.store, "key1")
+
+	if !mustPanic(func() { cache.MGet([]string{"key2"}) }) {
+		t.Fatal("MGet on a corrupted cache should panic with debug checks enabled")
+	}
+	done := make(chan struct{})
+	go func() {
+		cache.Len()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Len blocked after a failed debug check in MGet: the lock is still held")
+	}
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Repeatedly getting the MRU key hits the fast path: no relinking, no allocation
+func BenchmarkGetHead(b *testing.B) {
+	cache, _ := NewStringCache(100)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	cache.Get("key0")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get("key0")
+	}
+}
+
+// Alternating between two keys forces a promotion on every Get, for comparison
+func BenchmarkGetAlternating(b *testing.B) {
+	cache, _ := NewStringCache(100)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%2 == 0 {
+			cache.Get("key0")
+		} else {
+			cache.Get("key1")
+		}
+	}
+}
+
+// Cycling through every key moves each one from the tail to the head: all Gets promote unless batched
+func benchmarkCycling(b *testing.B, opts ...Option) {
+	cache, _ := NewStringCache(100, opts...)
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+		cache.Set(keys[i], "value")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkGetCycling(b *testing.B) {
+	benchmarkCycling(b)
+}
+
+// With a batch of 8 only one Get in 8 relinks a node
+func BenchmarkGetCyclingBatched(b *testing.B) {
+	benchmarkCycling(b, WithPromotionBatching(8))
+}
+
+func benchmarkMixed(b *testing.B, cache *StringCache) {
+	keys := make([]string, 200)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		if i%4 == 0 {
+			cache.Set(key, "value")
+		} else {
+			cache.Get(key)
+		}
+	}
+}
+
+// The unsafe cache skips the mutex, the difference is the cost of locking on the hot path
+func BenchmarkMixedSafe(b *testing.B) {
+	cache, _ := NewStringCache(100)
+	benchmarkMixed(b, cache)
+}
+
+func BenchmarkMixedUnsafe(b *testing.B) {
+	cache, _ := NewUnsafeCache[string, string](100)
+	benchmarkMixed(b, cache)
+}
+
+type benchmarkedCache interface {
+	Get(key string) (string, bool)
+	Set(key, value string) bool
+}
+
+// Goroutines on every core hammer the cache, with one Set for three Gets
+func benchmarkParallel(b *testing.B, cache benchmarkedCache) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%4 == 0 {
+				cache.Set(key, "value")
+			} else {
+				cache.Get(key)
+			}
+			i++
+		}
+	})
+}
+
+// The single mutex serializes every operation
+func BenchmarkParallelSingleMutex(b *testing.B) {
+	cache, _ := NewStringCache(500)
+	benchmarkParallel(b, cache)
+}
+
+// With 16 shards concurrent operations mostly take different locks
+func BenchmarkParallelSharded(b *testing.B) {
+	cache, _ := NewShardedCache[string, string](500, 16)
+	benchmarkParallel(b, cache)
+}
+
+// Every Set inserts a new key into a full cache: one node in, one node evicted
+func BenchmarkSetChurn(b *testing.B) {
+	cache, _ := NewStringCache(100)
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Set(keys[i%len(keys)], "value")
+	}
+}
+
+// A hot key read between writes of others is promoted on most Gets, which relink it in place
+func BenchmarkGetHotKey(b *testing.B) {
+	cache, _ := NewStringCache(100)
+	for i := 0; i < 100; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get("key0")
+		cache.Get("key1")
+	}
+}
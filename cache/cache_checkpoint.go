@@ -0,0 +1,236 @@
+package cache
+
+import "maps"
+
+// A checkpoint is a deep copy of the cache internals: a freshly linked DLL mirroring the original
+// one (same order, nodes copied field by field), a matching store and copies of the counters.
+// Restoring copies the checkpoint once more, so a checkpoint is never shared with a live cache and
+// can be restored any number of times. Clone relies on the same copy to build an independent cache
+
+// CacheState is an opaque in-memory copy of the state of a cache, see Checkpoint.
+// It is not meant to be serialized, use it to go back in time within the same process
+type CacheState[K comparable, V any] struct {
+	capacity   int
+	head       *cacheNode[K, V]
+	store      map[K]*cacheNode[K, V]
+	seq        uint64
+	stats      cacheStats
+	dependents map[K]map[K]struct{}
+	dependsOn  map[K][]K
+	opsRate    *opsRate
+	evictions  *evictionHistory[K]
+	uniqueKeys *hyperLogLog
+	index      map[string]K
+}
+
+// Checkpoint captures the contents, the access order and the counters of the cache, Stats included
+func (cache *LruCache[K, V]) Checkpoint() *CacheState[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.checkpoint()
+}
+
+// Restore puts the cache back in the state captured by Checkpoint, dropping its current contents.
+// Options are not part of the state, the cache keeps the ones it was created with
+func (cache *LruCache[K, V]) Restore(state *CacheState[K, V]) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.load(state.copy())
+}
+
+// Clone returns an independent copy of the cache: same capacity and options, same contents in
+// the same order, same counters. The two caches share no nodes and can be mutated separately.
+// The clone doesn't write to the audit log nor to the write-behind store of the original,
+// doesn't wait for its pending futures and starts with an empty eviction trace
+func (cache *LruCache[K, V]) Clone() *LruCache[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// copying the struct carries the configuration over,
+	// everything holding state is then replaced by a copy
+	clone := *cache
+	clone.mutex = cache.newMutex()
+	if cache.fairness != nil {
+		clone.fairness = &prefixFairness[K]{
+			prefixOf: cache.fairness.prefixOf,
+			share:    cache.fairness.share,
+			limit:    cache.fairness.limit,
+			counts:   make(map[string]int),
+		}
+	}
+	clone.audit = nil
+	clone.writeBehind = nil
+	clone.futures = nil
+	clone.janitor = nil
+	clone.subscribers = nil
+	if cache.trace != nil {
+		clone.trace = &evictionTrace[K]{}
+	}
+	clone.load(cache.checkpoint())
+	return &clone
+}
+
+// checkpoint copies the live state of the cache
+func (cache *LruCache[K, V]) checkpoint() *CacheState[K, V] {
+	live := CacheState[K, V]{
+		capacity:   cache.capacity,
+		head:       cache.head,
+		store:      cache.store,
+		seq:        cache.seq,
+		stats:      cache.stats,
+		dependents: cache.dependents,
+		dependsOn:  cache.dependsOn,
+		opsRate:    cache.opsRate,
+		evictions:  cache.evictions,
+		uniqueKeys: cache.uniqueKeys,
+	}
+	if cache.index != nil {
+		live.index = cache.index.keys
+	}
+	return live.copy()
+}
+
+// load makes state the live state of the cache, state must not be used afterward
+func (cache *LruCache[K, V]) load(state *CacheState[K, V]) {
+	cache.capacity = state.capacity
+	cache.head, cache.store = state.head, state.store
+	cache.seq = state.seq
+	cache.stats = state.stats
+	cache.dependents, cache.dependsOn = state.dependents, state.dependsOn
+
+	// counters are only restored if the feature is enabled on this cache
+	if cache.opsRate != nil {
+		cache.opsRate = state.opsRate
+		if cache.opsRate == nil {
+			cache.opsRate = &opsRate{}
+		}
+	}
+	if cache.evictions != nil {
+		cache.evictions = state.evictions
+		if cache.evictions == nil {
+			cache.evictions = &evictionHistory[K]{}
+		}
+	}
+	if cache.uniqueKeys != nil {
+		cache.uniqueKeys = state.uniqueKeys
+		if cache.uniqueKeys == nil {
+			cache.uniqueKeys = newHyperLogLog()
+		}
+	}
+	if cache.index != nil {
+		cache.index = &secondaryIndex[K, V]{extract: cache.index.extract, keys: state.index}
+		if cache.index.keys == nil {
+			// the state comes from a cache without index, rebuild it
+			cache.index.keys = make(map[string]K, len(cache.store))
+			for _, node := range cache.store {
+				cache.indexNode(node)
+			}
+		}
+	}
+
+	// per priority and per prefix counts, sizes, and interned values are derived from the entries
+	cache.priorities = nil
+	cache.currentBytes = 0
+	for _, node := range cache.store {
+		cache.trackPriority(node.priority, 1)
+		cache.currentBytes += entrySize(node.key, node.value)
+	}
+	if cache.fairness != nil {
+		// the state may come from before a Resize
+		cache.fairness.resize(cache.capacity)
+		clear(cache.fairness.counts)
+		for key := range cache.store {
+			cache.trackPrefix(key, 1)
+		}
+	}
+	if cache.interned != nil {
+		cache.interned = newInternTable()
+		for _, node := range cache.store {
+			node.value = cache.internValue(node.value)
+		}
+	}
+}
+
+// copy returns a deep copy of the state
+func (state *CacheState[K, V]) copy() *CacheState[K, V] {
+	head, store := copyList(state.head, len(state.store))
+	return &CacheState[K, V]{
+		capacity:   state.capacity,
+		head:       head,
+		store:      store,
+		seq:        state.seq,
+		stats:      state.stats,
+		dependents: copyDependents(state.dependents),
+		dependsOn:  copyDependsOn(state.dependsOn),
+		opsRate:    copyOf(state.opsRate),
+		evictions:  copyOf(state.evictions),
+		uniqueKeys: copyOf(state.uniqueKeys),
+		index:      maps.Clone(state.index),
+	}
+}
+
+// copyList copies the DLL starting at head into a new, independent, circular DLL
+// and returns its head along with a store indexing the copied nodes
+func copyList[K comparable, V any](head *cacheNode[K, V], size int) (*cacheNode[K, V], map[K]*cacheNode[K, V]) {
+	store := make(map[K]*cacheNode[K, V], size)
+	if head == nil {
+		return nil, store
+	}
+
+	var newHead, last *cacheNode[K, V]
+	node := head
+	for {
+		copied := *node
+		if newHead == nil {
+			newHead = &copied
+		} else {
+			last.next = &copied
+			copied.prev = last
+		}
+		store[copied.key] = &copied
+		last = &copied
+
+		node = node.next
+		if node == head {
+			break
+		}
+	}
+
+	// close the circle
+	last.next = newHead
+	newHead.prev = last
+	return newHead, store
+}
+
+func copyDependents[K comparable](dependents map[K]map[K]struct{}) map[K]map[K]struct{} {
+	copied := make(map[K]map[K]struct{}, len(dependents))
+	for parent, set := range dependents {
+		copied[parent] = make(map[K]struct{}, len(set))
+		for key := range set {
+			copied[parent][key] = struct{}{}
+		}
+	}
+	return copied
+}
+
+func copyDependsOn[K comparable](dependsOn map[K][]K) map[K][]K {
+	copied := make(map[K][]K, len(dependsOn))
+	for key, parents := range dependsOn {
+		copied[key] = append([]K(nil), parents...)
+	}
+	return copied
+}
+
+// copyOf returns a pointer to a shallow copy of *value, nil if value is nil
+func copyOf[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
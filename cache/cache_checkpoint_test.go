@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointRestore(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(4, WithClock(clock.Now), WithOpsRateTracking())
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.SetWithDeps("key3", "value3", []string{"key1"})
+	cache.Get("key1")
+
+	wantKeys := listKeys(cache)
+	wantInsertion := cache.InsertionOrder()
+	gets, sets, deletes := cache.OpsPerSecond()
+	wantStats := cache.Stats()
+
+	state := cache.Checkpoint()
+
+	// mutate everything the checkpoint covers
+	cache.Set("key4", "value4")
+	cache.Set("key5", "value5")
+	cache.Set("key2", "value2-updated")
+	cache.Delete("key1")
+	cache.Get("key4")
+	cache.Get("missing")
+
+	for i := 0; i < 2; i++ {
+		cache.Restore(state)
+
+		if got := listKeys(cache); !reflect.DeepEqual(got, wantKeys) {
+			t.Errorf("keys after Restore = %v, want %v", got, wantKeys)
+		}
+		if got := cache.InsertionOrder(); !reflect.DeepEqual(got, wantInsertion) {
+			t.Errorf("InsertionOrder() after Restore = %v, want %v", got, wantInsertion)
+		}
+		if stats := cache.Stats(); stats != wantStats {
+			t.Errorf("Stats() after Restore = %+v, want %+v", stats, wantStats)
+		}
+		if g, s, d := cache.OpsPerSecond(); g != gets || s != sets || d != deletes {
+			t.Errorf("OpsPerSecond() after Restore = (%v, %v, %v), want (%v, %v, %v)", g, s, d, gets, sets, deletes)
+		}
+		if val, ok := cache.Get("key2"); !ok || val != "value2" {
+			t.Errorf("Get(key2) after Restore = (%v, %v), want (value2, true)", val, ok)
+		}
+		if err := verifyIntegrity(cache); err != nil {
+			t.Errorf("integrity check failed after Restore: %v", err)
+		}
+
+		// the restored dependency graph is live, and mutating the cache must not alter the checkpoint
+		if ok, cascaded := cache.DeleteWithDeps("key1"); !ok || cascaded != 1 {
+			t.Errorf("DeleteWithDeps(key1) after Restore = (%v, %d), want (true, 1)", ok, cascaded)
+		}
+	}
+}
+
+func TestCheckpointRestoreCapacity(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	cache.Set("key1", "value1")
+	state := cache.Checkpoint()
+
+	empty, _ := NewStringCache(5)
+	empty.Restore(state)
+	if empty.Capacity() != 2 || empty.Len() != 1 {
+		t.Errorf("restored cache has capacity %d and %d entries, want 2 and 1", empty.Capacity(), empty.Len())
+	}
+
+	// an empty checkpoint empties the cache
+	blank, _ := NewStringCache(2)
+	cache.Restore(blank.Checkpoint())
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after restoring an empty state, want 0", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(3, WithClock(clock.Now), WithPrefixFairness(tenantOf, 1))
+	cache.Set("a:1", "value1")
+	cache.Set("a:2", "value2")
+	cache.SetWithDeps("b:1", "value3", []string{"a:1"})
+	cache.Get("a:1")
+
+	wantKeys := listKeys(cache)
+	clone := cache.Clone()
+
+	if got := listKeys(clone); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("clone keys = %v, want %v", got, wantKeys)
+	}
+	if clone.Capacity() != cache.Capacity() {
+		t.Errorf("clone capacity = %d, want %d", clone.Capacity(), cache.Capacity())
+	}
+
+	// no node is shared
+	for key, node := range cache.store {
+		if clone.store[key] == node {
+			t.Errorf("node for %s is shared between the cache and its clone", key)
+		}
+	}
+
+	// mutating the clone leaves the original untouched
+	clone.Set("a:3", "value4")
+	clone.Set("a:2", "updated")
+	clone.Delete("a:1")
+	if got := listKeys(cache); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("original keys after mutating the clone = %v, want %v", got, wantKeys)
+	}
+	if val, ok := cache.Get("a:2"); !ok || val != "value2" {
+		t.Errorf("original Get(a:2) = (%v, %v), want (value2, true)", val, ok)
+	}
+	if _, ok := clone.Get("b:1"); ok {
+		t.Error("b:1 should have been removed from the clone along with a:1")
+	}
+	if _, ok := cache.Get("b:1"); !ok {
+		t.Error("b:1 should still be in the original")
+	}
+
+	for _, c := range []*StringCache{cache, clone} {
+		if err := verifyIntegrity(c); err != nil {
+			t.Errorf("integrity check failed: %v", err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 )
 
 // If all sequential tests pass, then only race conditions need to be checked
@@ -11,7 +12,7 @@ import (
 // affect the integrity of the cache it should pass the test
 
 func TestCacheConcurrency(t *testing.T) {
-	cache, _ := NewCache(5)
+	cache, _ := NewStringCache(5)
 	var wg sync.WaitGroup
 
 	// Define test parameters as constants for better maintainability
@@ -42,3 +43,214 @@ func TestCacheConcurrency(t *testing.T) {
 		t.Fatalf("integrity check failed after concurrent access: %v", err)
 	}
 }
+
+// Gets read under the read lock and promote under the write lock, in between Sets and Deletes may
+// evict or replace the entry they read. The cache must stay consistent and Gets must only ever
+// return a value that was set for their key
+func TestCacheConcurrentReadsAndWrites(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	var wg sync.WaitGroup
+
+	const (
+		numReaders    = 50
+		numWriters    = 10
+		opsPerRoutine = 200
+		numUniqueKeys = 20
+	)
+
+	for j := 0; j < numReaders; j++ {
+		wg.Add(1)
+		go func(routineNum int) {
+			defer wg.Done()
+			for i := 0; i < opsPerRoutine; i++ {
+				key := fmt.Sprintf("key%d", (i+routineNum)%numUniqueKeys)
+				if value, ok := cache.Get(key); ok && value != "value for "+key {
+					t.Errorf("Get(%s) = %q", key, value)
+				}
+			}
+		}(j)
+	}
+	for j := 0; j < numWriters; j++ {
+		wg.Add(1)
+		go func(routineNum int) {
+			defer wg.Done()
+			for i := 0; i < opsPerRoutine; i++ {
+				key := fmt.Sprintf("key%d", (i*routineNum)%numUniqueKeys)
+				if i%5 == 0 {
+					cache.Delete(key)
+				} else {
+					cache.Set(key, "value for "+key)
+				}
+			}
+		}(j)
+	}
+
+	wg.Wait()
+	if err := verifyIntegrity(cache); err != nil {
+		t.Fatalf("integrity check failed after concurrent access: %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits+stats.Misses != numReaders*opsPerRoutine {
+		t.Errorf("counted %d Gets, want %d", stats.Hits+stats.Misses, numReaders*opsPerRoutine)
+	}
+}
+
+// Consumers popping the same key: exactly one of them must get it
+func TestGetAndDeleteConcurrency(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("job", "payload")
+
+	const numConsumers = 100
+	var wg sync.WaitGroup
+	results := make(chan string, numConsumers)
+	for j := 0; j < numConsumers; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, ok := cache.GetAndDelete("job"); ok {
+				results <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	popped := 0
+	for value := range results {
+		popped++
+		if value != "payload" {
+			t.Errorf("GetAndDelete returned %q, want payload", value)
+		}
+	}
+	if popped != 1 {
+		t.Errorf("%d consumers got the key, want exactly 1", popped)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Fatalf("integrity check failed after concurrent access: %v", err)
+	}
+}
+
+// ForEach works on a snapshot, writers running meanwhile must not corrupt it nor the cache
+func TestForEachConcurrency(t *testing.T) {
+	cache, _ := NewStringCache(10)
+	var wg sync.WaitGroup
+
+	const (
+		numWriters    = 20
+		numIterators  = 10
+		opsPerRoutine = 100
+		numUniqueKeys = 30
+	)
+
+	for j := 0; j < numWriters; j++ {
+		wg.Add(1)
+		go func(routineNum int) {
+			defer wg.Done()
+			for i := 0; i < opsPerRoutine; i++ {
+				key := fmt.Sprintf("key%d", (i+routineNum)%numUniqueKeys)
+				if i%3 == 0 {
+					cache.Delete(key)
+				} else {
+					cache.Set(key, "value for "+key)
+				}
+			}
+		}(j)
+	}
+	for j := 0; j < numIterators; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < opsPerRoutine/10; i++ {
+				seen := make(map[string]bool)
+				cache.ForEach(func(key, value string) bool {
+					if seen[key] {
+						t.Errorf("ForEach visited %s twice", key)
+					}
+					seen[key] = true
+					if value != "value for "+key {
+						t.Errorf("ForEach passed %s=%s", key, value)
+					}
+					return true
+				})
+				if len(seen) > 10 {
+					t.Errorf("ForEach visited %d entries, capacity is 10", len(seen))
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	if err := verifyIntegrity(cache); err != nil {
+		t.Fatalf("integrity check failed after concurrent access: %v", err)
+	}
+}
+
+func TestGetUnderReadLock(t *testing.T) {
+	lru, _ := NewStringCache(3)
+	fifo, _ := NewStringCache(3, WithPolicy(PolicyFIFO))
+	for _, cache := range []*StringCache{lru, fifo} {
+		cache.Set("key1", "value1")
+		cache.Set("key2", "value2")
+	}
+
+	// a reader holding the lock doesn't block Gets that change nothing but counters
+	gets := []struct {
+		name  string
+		cache *StringCache
+		key   string
+		ok    bool
+	}{
+		{"miss", lru, "missing", false},
+		{"head hit", lru, "key2", true},
+		{"FIFO hit", fifo, "key1", true},
+	}
+	for _, get := range gets {
+		get.cache.mutex.RLock()
+		done := make(chan bool)
+		go func() {
+			_, ok := get.cache.Get(get.key)
+			done <- ok
+		}()
+		select {
+		case ok := <-done:
+			if ok != get.ok {
+				t.Errorf("%s: Get(%s) ok = %v, want %v", get.name, get.key, ok, get.ok)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: Get(%s) waited for the write lock", get.name, get.key)
+		}
+		get.cache.mutex.RUnlock()
+	}
+	if stats := lru.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit and 1 miss", stats)
+	}
+}
+
+func TestBatchedPromotionConcurrency(t *testing.T) {
+	cache, _ := NewStringCache(10, WithPromotionBatching(4))
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	const numReaders, opsPerRoutine = 20, 100
+	var wg sync.WaitGroup
+	for j := 0; j < numReaders; j++ {
+		wg.Add(1)
+		go func(routineNum int) {
+			defer wg.Done()
+			for i := 0; i < opsPerRoutine; i++ {
+				cache.Get(fmt.Sprintf("key%d", (i+routineNum)%10))
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	if err := verifyIntegrity(cache); err != nil {
+		t.Fatalf("integrity check failed after concurrent batched hits: %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits != numReaders*opsPerRoutine {
+		t.Errorf("counted %d hits, want %d", stats.Hits, numReaders*opsPerRoutine)
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+// Entries can declare that they are derived from other keys. The dependency graph is kept
+// in two maps next to the store:
+//   - dependents: key -> set of keys derived from it, walked when key is removed
+//   - dependsOn: key -> keys it was derived from, used to clean dependents when key goes away
+//
+// Removing a key (Delete or eviction) removes everything that depends on it, transitively.
+// Cycles are harmless: a key is only removed once since it leaves the store on its first removal.
+
+// SetWithDeps behaves like Set and records that key is derived from the keys in dependsOn.
+// Deleting or evicting any of them later removes key too.
+// The recorded dependencies replace any previously recorded for key, while a plain Set
+// on an existing key keeps them
+func (cache *LruCache[K, V]) SetWithDeps(key K, value V, dependsOn []K) (updated bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	// an existing entry of key must not take the dependencies of a refused value
+	if !cache.fits(key, value) {
+		return false
+	}
+	updated = cache.set(key, value)
+
+	cache.clearDependencies(key)
+	for _, parent := range dependsOn {
+		// a key depending on itself would only delete itself
+		if parent == key {
+			continue
+		}
+		if _, ok := cache.dependents[parent]; !ok {
+			cache.dependents[parent] = make(map[K]struct{})
+		}
+		if _, ok := cache.dependents[parent][key]; ok {
+			continue
+		}
+		cache.dependents[parent][key] = struct{}{}
+		cache.dependsOn[key] = append(cache.dependsOn[key], parent)
+	}
+	return updated
+}
+
+// DeleteWithDeps is Delete, also reporting how many dependent entries were removed with key
+func (cache *LruCache[K, V]) DeleteWithDeps(key K) (ok bool, cascaded int) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	cache.countOp(opDelete)
+
+	// check if it exists, an expired entry is removed as such but reported missing
+	existing, ok := cache.store[key]
+	if ok && cache.expired(existing) {
+		cache.discard(existing, ReasonExpired)
+		return false, 0
+	}
+	if !ok {
+		return false, 0
+	}
+
+	return true, cache.discard(existing, ReasonDeleted)
+}
+
+// clearDependencies forgets the dependencies key was recorded with
+func (cache *LruCache[K, V]) clearDependencies(key K) {
+	for _, parent := range cache.dependsOn[key] {
+		delete(cache.dependents[parent], key)
+		if len(cache.dependents[parent]) == 0 {
+			delete(cache.dependents, parent)
+		}
+	}
+	delete(cache.dependsOn, key)
+}
+
+// takeDependents returns the keys depending on key and drops them from the index
+func (cache *LruCache[K, V]) takeDependents(key K) []K {
+	set := cache.dependents[key]
+	delete(cache.dependents, key)
+
+	keys := make([]K, 0, len(set))
+	for dependent := range set {
+		keys = append(keys, dependent)
+	}
+	return keys
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestDependencyCascade(t *testing.T) {
+	cache, _ := NewStringCache(10)
+
+	// root <- child <- grandchild, root <- sibling, other is unrelated
+	cache.Set("root", "r")
+	cache.SetWithDeps("child", "c", []string{"root"})
+	cache.SetWithDeps("grandchild", "g", []string{"child"})
+	cache.SetWithDeps("sibling", "s", []string{"root"})
+	cache.Set("other", "o")
+	if err := verifyIntegrity(cache); err != nil {
+		t.Fatalf("integrity check failed after SetWithDeps: %v", err)
+	}
+
+	ok, cascaded := cache.DeleteWithDeps("root")
+	if !ok || cascaded != 3 {
+		t.Errorf("DeleteWithDeps(root) = (%v, %d), want (true, 3)", ok, cascaded)
+	}
+	for _, key := range []string{"root", "child", "grandchild", "sibling"} {
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("%s should have been removed with root", key)
+		}
+	}
+	if _, ok := cache.Get("other"); !ok {
+		t.Error("unrelated key should survive the cascade")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after cascade: %v", err)
+	}
+}
+
+func TestDependencyCascadeMidChain(t *testing.T) {
+	cache, _ := NewStringCache(10)
+	cache.Set("a", "1")
+	cache.SetWithDeps("b", "2", []string{"a"})
+	cache.SetWithDeps("c", "3", []string{"b"})
+
+	// deleting the middle of the chain only removes what is below it
+	if ok := cache.Delete("b"); !ok {
+		t.Error("Delete(b) should report the key existed")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("a should survive deleting its dependent")
+	}
+	if _, ok := cache.Get("c"); ok {
+		t.Error("c should have been removed with b")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	cache, _ := NewStringCache(10)
+	cache.SetWithDeps("x", "1", []string{"y"})
+	cache.SetWithDeps("y", "2", []string{"x"})
+	cache.SetWithDeps("z", "3", []string{"z"})
+
+	ok, cascaded := cache.DeleteWithDeps("x")
+	if !ok || cascaded != 1 {
+		t.Errorf("DeleteWithDeps(x) = (%v, %d), want (true, 1)", ok, cascaded)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+
+	// self dependencies are ignored
+	ok, cascaded = cache.DeleteWithDeps("z")
+	if !ok || cascaded != 0 {
+		t.Errorf("DeleteWithDeps(z) = (%v, %d), want (true, 0)", ok, cascaded)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestDependencyEviction(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.Set("base", "b")
+	cache.SetWithDeps("derived", "d", []string{"base"})
+	cache.Set("other", "o")
+
+	// base is the LRU: evicting it takes derived along
+	cache.Set("new", "n")
+	if _, ok := cache.Get("base"); ok {
+		t.Error("base should have been evicted")
+	}
+	if _, ok := cache.Get("derived"); ok {
+		t.Error("derived should have been removed with base")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after eviction cascade: %v", err)
+	}
+}
+
+func TestDependencyUpdate(t *testing.T) {
+	cache, _ := NewStringCache(10)
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	cache.SetWithDeps("c", "3", []string{"a"})
+
+	// plain Set keeps dependencies
+	cache.Set("c", "3-updated")
+	cache.Delete("a")
+	if _, ok := cache.Get("c"); ok {
+		t.Error("c should still depend on a after a plain Set")
+	}
+
+	// SetWithDeps replaces them
+	cache.SetWithDeps("d", "4", []string{"b"})
+	cache.SetWithDeps("d", "4", []string{"e"})
+	cache.Delete("b")
+	if _, ok := cache.Get("d"); !ok {
+		t.Error("d should no longer depend on b")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import "fmt"
+
+// Subscribers get the changes of the cache as events on a buffered channel. Events are published
+// under the lock, in the order of the changes, but never block: an event that doesn't fit in the
+// buffer of a subscriber is dropped for that subscriber, a slow reader loses events rather than
+// stalling the cache
+
+// subscriberBuffer is the capacity of the channel of every subscriber
+const subscriberBuffer = 128
+
+// EventType tells what change an Event reports
+type EventType int
+
+const (
+	// EventSet reports an insertion or an update, with the new value
+	EventSet EventType = iota
+	// EventEvict reports an entry evicted to make room for another one
+	EventEvict
+	// EventDelete reports any other removal: deleted, expired, or cascaded from a dependency
+	EventDelete
+	// EventClear reports that every entry was removed at once, by Clear, Load or Rotate
+	EventClear
+)
+
+func (eventType EventType) String() string {
+	switch eventType {
+	case EventSet:
+		return "set"
+	case EventEvict:
+		return "evict"
+	case EventDelete:
+		return "delete"
+	case EventClear:
+		return "clear"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(eventType))
+	}
+}
+
+// Event is a change of the cache, Key and Value are zero for EventClear
+type Event[K comparable, V any] struct {
+	Type  EventType
+	Key   K
+	Value V
+}
+
+// Subscribe returns a channel receiving an Event for every later change of the cache, until
+// Unsubscribe or Close. Restore replaces the contents without reporting it
+func (cache *LruCache[K, V]) Subscribe() <-chan Event[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	subscriber := make(chan Event[K, V], subscriberBuffer)
+	cache.subscribers = append(cache.subscribers, subscriber)
+	return subscriber
+}
+
+// Unsubscribe stops publishing to a channel returned by Subscribe and closes it.
+// Events still buffered can be drained. Unknown channels are ignored
+func (cache *LruCache[K, V]) Unsubscribe(events <-chan Event[K, V]) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for i, subscriber := range cache.subscribers {
+		if subscriber == events {
+			cache.subscribers = append(cache.subscribers[:i], cache.subscribers[i+1:]...)
+			close(subscriber)
+			return
+		}
+	}
+}
+
+// unsubscribeAll closes every subscription
+func (cache *LruCache[K, V]) unsubscribeAll() {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, subscriber := range cache.subscribers {
+		close(subscriber)
+	}
+	cache.subscribers = nil
+}
+
+// publish sends event to every subscriber with room for it
+func (cache *LruCache[K, V]) publish(eventType EventType, key K, value V) {
+	for _, subscriber := range cache.subscribers {
+		select {
+		case subscriber <- Event[K, V]{Type: eventType, Key: key, Value: value}:
+		default:
+		}
+	}
+}
+
+// publishRemoval reports the removal of an entry
+func (cache *LruCache[K, V]) publishRemoval(node *cacheNode[K, V], reason RemovalReason) {
+	if len(cache.subscribers) == 0 {
+		return
+	}
+	if reason == ReasonEvicted {
+		cache.publish(EventEvict, node.key, node.value)
+	} else {
+		cache.publish(EventDelete, node.key, node.value)
+	}
+}
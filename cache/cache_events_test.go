@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// drain returns the events buffered in events
+func drain(events <-chan Event[string, string]) []Event[string, string] {
+	var received []Event[string, string]
+	for {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	events := cache.Subscribe()
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key1", "updated")
+	cache.Set("key3", "value3") // evicts key2
+	cache.Delete("key1")
+	cache.Get("key3") // reads aren't published
+	cache.Clear()
+
+	want := []Event[string, string]{
+		{EventSet, "key1", "value1"},
+		{EventSet, "key2", "value2"},
+		{EventSet, "key1", "updated"},
+		{EventEvict, "key2", "value2"},
+		{EventSet, "key3", "value3"},
+		{EventDelete, "key1", "updated"},
+		{Type: EventClear},
+	}
+	if got := drain(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	cache.Unsubscribe(events)
+	if _, open := <-events; open {
+		t.Error("Unsubscribe should close the channel")
+	}
+	// unknown or already removed channels are ignored
+	cache.Unsubscribe(events)
+	cache.Set("key4", "value4")
+}
+
+// Nobody reads the channel: once its buffer is full events are dropped, the cache goes on
+func TestSubscribeSlowReader(t *testing.T) {
+	cache, _ := NewStringCache(10)
+	events := cache.Subscribe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10*subscriberBuffer; i++ {
+			cache.Set("key", "value")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("an unread subscriber blocked the cache")
+	}
+
+	if got := len(drain(events)); got != subscriberBuffer {
+		t.Errorf("received %d events, want the %d buffered", got, subscriberBuffer)
+	}
+	cache.Close()
+	if _, open := <-events; open {
+		t.Error("Close should close the subscriptions")
+	}
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// The eviction history keeps the key and time of the most recent evictions in a fixed-size ring,
+// the oldest record being overwritten once it is full, for ThrashingKeys.
+// EvictionsOverTime doesn't need the keys, and must not lose counts under heavy eviction: it
+// reads per second counters over the last hour instead. As for the ops rate, they live in a ring
+// indexed by their absolute second, a slot holding an older second is reset when the clock reaches
+// it again. Queries aggregate the counters on demand into windows of whole seconds, which the
+// counters fill exactly
+
+const (
+	evictionHistorySize = 1024
+
+	evictionCountBuckets = 3600
+	evictionCountWidth   = time.Second
+)
+
+type evictionRecord[K comparable] struct {
+	key K
+	at  time.Time
+}
+
+type evictionHistory[K comparable] struct {
+	records [evictionHistorySize]evictionRecord[K]
+	// next slot to write, and how many slots hold a record
+	next int
+	size int
+
+	counts [evictionCountBuckets]evictionCount
+}
+
+type evictionCount struct {
+	id    int64
+	count int
+}
+
+// WithEvictionHistory enables EvictionsOverTime by recording the last evictions
+func WithEvictionHistory() Option {
+	return func(o *options) {
+		o.evictionHistory = true
+	}
+}
+
+func (history *evictionHistory[K]) add(record evictionRecord[K]) {
+	history.records[history.next] = record
+	history.next = (history.next + 1) % evictionHistorySize
+	if history.size < evictionHistorySize {
+		history.size++
+	}
+
+	id := record.at.UnixNano() / int64(evictionCountWidth)
+	bucket := &history.counts[id%evictionCountBuckets]
+	if bucket.id != id {
+		*bucket = evictionCount{id: id}
+	}
+	bucket.count++
+}
+
+// each calls fn on the retained records, oldest first
+func (history *evictionHistory[K]) each(fn func(record evictionRecord[K])) {
+	start := (history.next - history.size + evictionHistorySize) % evictionHistorySize
+	for i := 0; i < history.size; i++ {
+		fn(history.records[(start+i)%evictionHistorySize])
+	}
+}
+
+// recordEviction adds key to the history if the removal was a capacity eviction
+func (cache *LruCache[K, V]) recordEviction(key K, reason RemovalReason) {
+	if cache.evictions != nil && reason == ReasonEvicted {
+		cache.evictions.add(evictionRecord[K]{key: key, at: cache.now()})
+	}
+}
+
+// EvictionsOverTime counts the capacity evictions of the last buckets windows of bucketDuration.
+// Counts are ordered from the oldest window to the most recent one. Evictions are counted per
+// second over the last hour, older ones are not counted: windows are made of whole seconds, the
+// last one ending with the current second. It returns an error if bucketDuration is not a positive
+// multiple of a second.
+// It always returns zeros unless the cache was created WithEvictionHistory
+func (cache *LruCache[K, V]) EvictionsOverTime(buckets int, bucketDuration time.Duration) ([]int, error) {
+	if bucketDuration <= 0 || bucketDuration%evictionCountWidth != 0 {
+		return nil, fmt.Errorf("bucket duration must be a positive multiple of %v, got %v", evictionCountWidth, bucketDuration)
+	}
+	if buckets < 0 {
+		buckets = 0
+	}
+	counts := make([]int, buckets)
+
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.evictions == nil {
+		return counts, nil
+	}
+
+	id := cache.now().UnixNano() / int64(evictionCountWidth)
+	width := int64(bucketDuration / evictionCountWidth)
+	for _, bucket := range cache.evictions.counts {
+		if bucket.count == 0 || bucket.id <= id-evictionCountBuckets || bucket.id > id {
+			continue
+		}
+		// number of windows between the second and the current one, 0 being the last window
+		age := int((id - bucket.id) / width)
+		if age < buckets {
+			counts[buckets-1-age] += bucket.count
+		}
+	}
+	return counts, nil
+}
+
+// ThrashingKeys returns the keys evicted then inserted again more than threshold times within the
+// retained evictions: entries the cache keeps losing while they are still needed, a sign it is
+// undersized. Keys are ordered by their oldest retained eviction. Only the last 1024 evictions are
+// considered, and it always returns nil unless the cache was created WithEvictionHistory
+func (cache *LruCache[K, V]) ThrashingKeys(threshold int) []K {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.evictions == nil {
+		return nil
+	}
+
+	var evicted []K
+	evictions := make(map[K]int)
+	cache.evictions.each(func(record evictionRecord[K]) {
+		if evictions[record.key] == 0 {
+			evicted = append(evicted, record.key)
+		}
+		evictions[record.key]++
+	})
+
+	var keys []K
+	for _, key := range evicted {
+		// a key that is gone wasn't inserted again after its last eviction
+		reinsertions := evictions[key]
+		if _, present := cache.store[key]; !present {
+			reinsertions--
+		}
+		if reinsertions > threshold {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvictionsOverTime(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(2, WithClock(clock.Now), WithEvictionHistory())
+	cache.Set("fill1", "value")
+	cache.Set("fill2", "value")
+
+	// one eviction per Set from now on: 3 in the first minute, none in the second, 1 in the third
+	insert := func(n int) {
+		for i := 0; i < n; i++ {
+			cache.Set(fmt.Sprintf("key-%d-%d", clock.Now().Unix(), i), "value")
+		}
+	}
+	insert(3)
+	clock.Advance(time.Minute)
+	clock.Advance(time.Minute)
+	insert(1)
+	// deletions are not evictions
+	cache.Delete("fill1")
+	clock.Advance(30 * time.Second)
+
+	tests := []struct {
+		name     string
+		buckets  int
+		duration time.Duration
+		want     []int
+	}{
+		{"per minute", 3, time.Minute, []int{3, 0, 1}},
+		{"more buckets than history", 5, time.Minute, []int{0, 0, 3, 0, 1}},
+		{"single wide bucket", 1, time.Hour, []int{4}},
+		{"narrow buckets", 2, 10 * time.Second, []int{0, 0}},
+		{"no buckets", 0, time.Minute, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := cache.EvictionsOverTime(tt.buckets, tt.duration); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EvictionsOverTime(%d, %v) = %v, want %v", tt.buckets, tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvictionCountsRetention(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(1, WithClock(clock.Now), WithEvictionHistory())
+	cache.Set("first", "value")
+
+	// counts don't depend on how many evictions the key records retain
+	evictions := evictionHistorySize + 10
+	for i := 0; i < evictions; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	if got, _ := cache.EvictionsOverTime(1, time.Minute); got[0] != evictions {
+		t.Errorf("EvictionsOverTime(1, 1m) = %v, want [%d]", got, evictions)
+	}
+
+	// the counts span an hour, the slots of older seconds are reused
+	clock.Advance(90 * time.Minute)
+	cache.Set("late", "value")
+	if got, _ := cache.EvictionsOverTime(2, time.Hour); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("EvictionsOverTime(2, 1h) = %v, want [0 1]", got)
+	}
+}
+
+func TestEvictionsOverTimeWindows(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(1, WithClock(clock.Now), WithEvictionHistory())
+	cache.Set("first", "value")
+
+	// windows are whole seconds: an eviction late in a second stays in the window of that second
+	clock.Advance(950 * time.Millisecond)
+	cache.Set("key1", "value")
+	clock.Advance(100 * time.Millisecond)
+	cache.Set("key2", "value")
+	if got, err := cache.EvictionsOverTime(3, time.Second); err != nil || !reflect.DeepEqual(got, []int{0, 1, 1}) {
+		t.Errorf("EvictionsOverTime(3, 1s) = (%v, %v), want ([0 1 1], nil)", got, err)
+	}
+
+	for _, duration := range []time.Duration{0, -time.Second, 100 * time.Millisecond, 1500 * time.Millisecond} {
+		if _, err := cache.EvictionsOverTime(3, duration); err == nil {
+			t.Errorf("EvictionsOverTime(3, %v) should fail", duration)
+		}
+	}
+}
+
+func TestEvictionsOverTimeDisabled(t *testing.T) {
+	cache, _ := NewStringCache(1)
+	cache.Set("key1", "value")
+	cache.Set("key2", "value")
+
+	if got, _ := cache.EvictionsOverTime(2, time.Minute); !reflect.DeepEqual(got, []int{0, 0}) {
+		t.Errorf("EvictionsOverTime without history = %v, want [0 0]", got)
+	}
+}
+
+func TestThrashingKeys(t *testing.T) {
+	cache, _ := NewStringCache(2, WithEvictionHistory())
+	cache.Set("stable", "value")
+
+	// hot is needed all along but keeps being pushed out by one-off keys
+	for i := 0; i < 5; i++ {
+		cache.Set("hot", "value")
+		cache.Get("stable")
+		cache.Set(fmt.Sprintf("scan%d", i), "value")
+		cache.Get("stable")
+	}
+
+	if got := cache.ThrashingKeys(3); !reflect.DeepEqual(got, []string{"hot"}) {
+		t.Errorf("ThrashingKeys(3) = %v, want [hot]", got)
+	}
+	// evicted 5 times but only inserted again 4 times since the first eviction
+	if got := cache.ThrashingKeys(4); len(got) != 0 {
+		t.Errorf("ThrashingKeys(4) = %v, want none", got)
+	}
+
+	untracked, _ := NewStringCache(2)
+	if got := untracked.ThrashingKeys(0); got != nil {
+		t.Errorf("ThrashingKeys without history = %v, want nil", got)
+	}
+}
@@ -0,0 +1,52 @@
+package cache
+
+import "time"
+
+// Entries can be considered expired when they were not accessed within the WithMaxIdle window,
+// or once the deadline given to SetWithTTL is past, whichever comes first.
+// Expiration is lazy: an expired entry stays in the DLL until an access finds it dead and removes it,
+// it then behaves exactly as if it had been deleted
+
+// WithMaxIdle expires entries that were neither set nor read during the last d.
+// Unlike a TTL there is no absolute deadline, an entry accessed regularly never expires
+func WithMaxIdle(d time.Duration) Option {
+	return func(o *options) {
+		o.maxIdle = d
+	}
+}
+
+// SetWithTTL is Set, the entry expiring ttl from now whether it is accessed or not.
+// Entries stored by Set never reach a deadline, and a Set of an existing key removes its TTL.
+// A ttl <= 0 stores an entry without deadline, as Set does. A value too large for the byte budget
+// of a NewSizeCache is dropped, as by Set
+func (cache *LruCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) (updated bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	// an existing entry of key must not take the deadline of a refused value
+	if !cache.fits(key, value) {
+		return false
+	}
+	updated = cache.set(key, value)
+	if ttl > 0 {
+		cache.store[key].expiresAt = cache.now().Add(ttl)
+	}
+	return updated
+}
+
+// touch records an access to node
+func (cache *LruCache[K, V]) touch(node *cacheNode[K, V]) {
+	if cache.maxIdle > 0 {
+		node.lastAccessedAt = cache.now()
+	}
+}
+
+// expired reports whether node is logically dead and should be treated as absent
+func (cache *LruCache[K, V]) expired(node *cacheNode[K, V]) bool {
+	if !node.expiresAt.IsZero() && cache.now().After(node.expiresAt) {
+		return true
+	}
+	return cache.maxIdle > 0 && cache.now().Sub(node.lastAccessedAt) > cache.maxIdle
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxIdle(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now), WithMaxIdle(time.Minute))
+	cache.Set("active", "value")
+	cache.Set("idle", "value")
+
+	// active is read every 30s, well within the window, while idle is left alone
+	for i := 0; i < 5; i++ {
+		clock.Advance(30 * time.Second)
+		if _, ok := cache.Get("active"); !ok {
+			t.Fatalf("active expired after %v despite regular access", time.Duration(i+1)*30*time.Second)
+		}
+	}
+
+	if _, ok := cache.Get("idle"); ok {
+		t.Error("idle should have expired")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 once the idle entry is purged", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after expiry: %v", err)
+	}
+}
+
+func TestMaxIdleBoundary(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now), WithMaxIdle(time.Minute))
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	// idle for exactly the window is still alive, a Set counts as an access
+	clock.Advance(time.Minute)
+	if _, ok := cache.Get("key1"); !ok {
+		t.Error("key1 idle for exactly the window should still be alive")
+	}
+	cache.Set("key2", "value2-updated")
+
+	clock.Advance(time.Minute + time.Nanosecond)
+	for _, key := range []string{"key1", "key2"} {
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("%s should have expired", key)
+		}
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after expiry: %v", err)
+	}
+}
+
+func TestNoMaxIdle(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now))
+	cache.Set("key1", "value1")
+
+	clock.Advance(24 * time.Hour)
+	if _, ok := cache.Get("key1"); !ok {
+		t.Error("entries should never expire without WithMaxIdle")
+	}
+}
+
+func TestSetOnExpiredEntry(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(3, WithClock(clock.Now), WithMaxIdle(time.Minute))
+	cache.Set("base", "value")
+	cache.SetWithDeps("key1", "value1", []string{"base"})
+	cache.Set("key2", "value2")
+
+	clock.Advance(2 * time.Minute)
+	cache.Get("key2")
+
+	// key1 expired, setting it again is an insertion and not an update
+	if updated := cache.Set("key1", "value1-new"); updated {
+		t.Error("Set on an expired entry should not report an update")
+	}
+	if cache.head.key != "key1" {
+		t.Errorf("head = %s, want key1", cache.head.key)
+	}
+	order := cache.InsertionOrder()
+	if last := order[len(order)-1]; last.Key != "key1" || last.Value != "value1-new" {
+		t.Errorf("last inserted = %v, want {key1 value1-new}", last)
+	}
+
+	// the new entry doesn't inherit the dependencies nor the idle time of the dead one
+	cache.Delete("base")
+	if val, ok := cache.Get("key1"); !ok || val != "value1-new" {
+		t.Errorf("Get(key1) = (%v, %v), want (value1-new, true)", val, ok)
+	}
+	clock.Advance(time.Minute)
+	if _, ok := cache.Get("key1"); !ok {
+		t.Error("key1 should be alive within a window of its insertion")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestSetWithTTL(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.SetWithTTL("token", "secret", 10*time.Millisecond)
+	cache.Set("plain", "value")
+
+	if value, ok := cache.Get("token"); !ok || value != "secret" {
+		t.Errorf("Get(token) before expiry = (%v, %v), want (secret, true)", value, ok)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cache.Get("token"); ok {
+		t.Error("token should have expired")
+	}
+	if _, ok := cache.Get("plain"); !ok {
+		t.Error("an entry stored by Set should never expire")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 once the expired entry is purged", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after expiry: %v", err)
+	}
+}
+
+func TestSetWithTTLDeadline(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now))
+	cache.SetWithTTL("key1", "value1", time.Minute)
+	cache.SetWithTTL("key2", "value2", time.Minute)
+	cache.SetWithTTL("key3", "value3", time.Minute)
+	// a Set of an existing key removes its deadline
+	cache.Set("key3", "value3")
+
+	// reads don't push the deadline back, unlike the idle window
+	clock.Advance(30 * time.Second)
+	cache.Get("key1")
+	clock.Advance(30 * time.Second)
+	if _, ok := cache.Peek("key1"); !ok {
+		t.Error("key1 should still be alive exactly at its deadline")
+	}
+
+	clock.Advance(time.Second)
+	if _, ok := cache.Peek("key1"); ok {
+		t.Error("Peek(key1) past the deadline should miss")
+	}
+	if _, ok := cache.Get("key2"); ok {
+		t.Error("Get(key2) past the deadline should miss")
+	}
+	if _, ok := cache.Get("key3"); !ok {
+		t.Error("key3 was Set again and should not expire")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 once expired entries are purged", cache.Len())
+	}
+}
+
+func TestUpdateKeepsTTL(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(3, WithClock(clock.Now))
+	cache.SetWithTTL("key1", "value1", time.Minute)
+
+	cache.Update("key1", func(old string) string { return "updated" })
+	clock.Advance(2 * time.Minute)
+	if cache.Contains("key1") {
+		t.Error("Update should keep the deadline of the entry")
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+// Prefix fairness bounds how much of the cache a single key prefix (a tenant) can occupy.
+// The number of live entries per prefix is kept up to date on insertion and removal, a Set that
+// would push a prefix over its limit evicts that prefix's own LRU entry instead of the global one.
+//
+// Finding the LRU entry of a prefix walks the DLL from the tail, so it costs O(n) in the worst
+// case, but only when a prefix is at its limit
+
+type prefixFairness[K comparable] struct {
+	prefixOf func(key K) string
+	share    float64
+	limit    int
+	counts   map[string]int
+}
+
+// WithPrefixFairness caps the share of the capacity any prefix can occupy to maxSharePerPrefix,
+// a fraction in (0, 1]. prefixFn extracts the prefix of a key. Every prefix is allowed at least
+// one entry
+func WithPrefixFairness[K comparable](prefixFn func(key K) string, maxSharePerPrefix float64) Option {
+	return func(o *options) {
+		o.prefixFn = prefixFn
+		o.maxPrefixShare = maxSharePerPrefix
+	}
+}
+
+func newPrefixFairness[K comparable](prefixFn func(key K) string, share float64, capacity int) *prefixFairness[K] {
+	fairness := &prefixFairness[K]{
+		prefixOf: prefixFn,
+		share:    share,
+		counts:   make(map[string]int),
+	}
+	fairness.resize(capacity)
+	return fairness
+}
+
+// resize recomputes the limit for a new capacity. Prefixes left over the limit are not trimmed,
+// each insertion under such a prefix still evicts one of its own entries
+func (fairness *prefixFairness[K]) resize(capacity int) {
+	fairness.limit = max(int(fairness.share*float64(capacity)), 1)
+}
+
+// fairnessVictim returns the node to evict so that inserting key keeps its prefix within the
+// limit, nil if the prefix still has room
+func (cache *LruCache[K, V]) fairnessVictim(key K) *cacheNode[K, V] {
+	if cache.fairness == nil || cache.head == nil {
+		return nil
+	}
+
+	prefix := cache.fairness.prefixOf(key)
+	if cache.fairness.counts[prefix] < cache.fairness.limit {
+		return nil
+	}
+
+	// walk from the tail (LRU) toward the head
+	node := cache.head.prev
+	for {
+		if cache.fairness.prefixOf(node.key) == prefix {
+			return node
+		}
+		if node == cache.head {
+			return nil
+		}
+		node = node.prev
+	}
+}
+
+// trackPrefix adjusts the entry count of key's prefix by delta
+func (cache *LruCache[K, V]) trackPrefix(key K, delta int) {
+	if cache.fairness == nil {
+		return
+	}
+
+	prefix := cache.fairness.prefixOf(key)
+	cache.fairness.counts[prefix] += delta
+	if cache.fairness.counts[prefix] == 0 {
+		delete(cache.fairness.counts, prefix)
+	}
+}
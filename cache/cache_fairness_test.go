@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func tenantOf(key string) string {
+	return strings.SplitN(key, ":", 2)[0]
+}
+
+func TestPrefixFairness(t *testing.T) {
+	cache, err := NewStringCache(10, WithPrefixFairness(tenantOf, 0.5))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	// the quiet tenant stores a few entries, then the noisy one floods the cache
+	for i := 0; i < 3; i++ {
+		cache.Set(fmt.Sprintf("quiet:%d", i), "value")
+	}
+	for i := 0; i < 20; i++ {
+		cache.Set(fmt.Sprintf("noisy:%d", i), "value")
+		if err := verifyIntegrity(cache); err != nil {
+			t.Fatalf("integrity check failed while flooding: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := cache.Get(fmt.Sprintf("quiet:%d", i)); !ok {
+			t.Errorf("quiet:%d should not be evicted by the noisy tenant", i)
+		}
+	}
+	// the noisy tenant keeps its 5 most recent entries
+	for i := 0; i < 20; i++ {
+		_, ok := cache.Get(fmt.Sprintf("noisy:%d", i))
+		if want := i >= 15; ok != want {
+			t.Errorf("Get(noisy:%d) ok = %v, want %v", i, ok, want)
+		}
+	}
+	if cache.Len() != 8 {
+		t.Errorf("Len() = %d, want 8", cache.Len())
+	}
+}
+
+func TestPrefixFairnessGlobalEviction(t *testing.T) {
+	cache, _ := NewStringCache(4, WithPrefixFairness(tenantOf, 0.5))
+
+	// both tenants within their share, a full cache falls back to global LRU
+	cache.Set("a:1", "value")
+	cache.Set("b:1", "value")
+	cache.Set("a:2", "value")
+	cache.Set("b:2", "value")
+	cache.Set("c:1", "value")
+
+	if _, ok := cache.Get("a:1"); ok {
+		t.Error("a:1 is the global LRU and should have been evicted")
+	}
+	if cache.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", cache.Len())
+	}
+
+	// deletions give room back to the tenant
+	cache.Delete("b:1")
+	cache.Delete("b:2")
+	cache.Set("b:3", "value")
+	cache.Set("b:4", "value")
+	for _, key := range []string{"a:2", "c:1", "b:3", "b:4"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s should be cached", key)
+		}
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestPrefixFairnessInvalidShare(t *testing.T) {
+	for _, share := range []float64{0, -0.5, 1.5} {
+		if _, err := NewStringCache(4, WithPrefixFairness(tenantOf, share)); err == nil {
+			t.Errorf("NewCache with share %v should fail", share)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+)
+
+// A future is a placeholder for a value being computed. While it is pending, reads of its key
+// wait for it instead of missing, then all get the same result: the value is computed once.
+// The placeholder lives next to the store, not in the DLL: it takes no room in the cache until
+// it resolves into a regular entry
+
+type future[V any] struct {
+	// closed once value or err is set
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// SetFuture stores a placeholder for key and computes its value with resolve in a new goroutine.
+// Reads of key wait until resolve returns, the value is then stored with Set semantics.
+// If resolve fails the placeholder is dropped, waiters get the error and the previous entry of key,
+// if any, is visible again. A panic in resolve is reported to the waiters as an error, it doesn't
+// reach the program. If a future is already pending for key, resolve is not called.
+// It returns an error for a cache created by NewUnsafeCache: resolve would race with its owner
+func (cache *LruCache[K, V]) SetFuture(key K, resolve func() (V, error)) error {
+	if !cache.IsConcurrencySafe() {
+		return fmt.Errorf("a future requires a concurrency safe cache")
+	}
+
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, pending := cache.futures[key]; pending {
+		return nil
+	}
+	go cache.resolveInBackground(key, cache.addFuture(key), resolve)
+	return nil
+}
+
+// addFuture stores a new pending future for key
+func (cache *LruCache[K, V]) addFuture(key K) *future[V] {
+	if cache.futures == nil {
+		cache.futures = make(map[K]*future[V])
+	}
+	pending := &future[V]{done: make(chan struct{})}
+	cache.futures[key] = pending
+	return pending
+}
+
+// resolveFuture runs resolve and settles pending with its result. Should resolve panic, the future
+// is failed and dropped before the panic goes on: its waiters and later reads of key must not
+// wait forever on a placeholder nobody will resolve
+// resolveInBackground is resolveFuture for a goroutine of its own. Nobody could recover a panic of
+// resolve there, the waiters already got it as an error: it is not raised again
+func (cache *LruCache[K, V]) resolveInBackground(key K, pending *future[V], resolve func() (V, error)) {
+	defer func() {
+		recover()
+	}()
+	cache.resolveFuture(key, pending, resolve)
+}
+
+func (cache *LruCache[K, V]) resolveFuture(key K, pending *future[V], resolve func() (V, error)) {
+	resolved := false
+	defer func() {
+		if resolved {
+			return
+		}
+		panicked := recover()
+
+		// protect DS
+		cache.mutex.Lock()
+		pending.err = fmt.Errorf("resolving future for key %v: panic: %v", key, panicked)
+		delete(cache.futures, key)
+		close(pending.done)
+		cache.mutex.Unlock()
+
+		panic(panicked)
+	}()
+
+	value, err := resolve()
+	resolved = true
+
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+
+	if err != nil {
+		pending.err = fmt.Errorf("resolving future for key %v: %w", key, err)
+	} else {
+		cache.set(key, value)
+		pending.value = value
+	}
+	delete(cache.futures, key)
+	close(pending.done)
+}
+
+// GetCtx is Get, waiting for a pending future of key (see SetFuture) until ctx is done.
+// It returns ctx.Err() if ctx is done first, or the error of the future if it failed.
+//
+// Entries are found under the read lock. Misses, and hits changing nothing but counters (the head
+// entry, any entry under PolicyFIFO, a batched hit not reaching the batch) are answered there,
+// unless the access must be timed (WithMaxIdle, WithOpsRateTracking) or counted (PolicyLFU), or
+// checked (WithDebugIntegrityChecks).
+// Other hits take the write lock afterward to promote the entry. Between the two locks the entry
+// may be updated or evicted: the value read first is returned anyway, and the node read is only
+// promoted if key still maps to it. Nodes are recycled, so it may by then hold a newer entry of
+// key: promoting that one is still an access to key. Recency is relaxed accordingly: an entry
+// evicted in that window is not brought back by this Get
+func (cache *LruCache[K, V]) GetCtx(ctx context.Context, key K) (value V, ok bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return value, false, err
+	}
+
+	// protect DS, readers only
+	cache.mutex.RLock()
+	pending := cache.futures[key]
+	node, found := cache.store[key]
+	answered := false
+	switch {
+	case pending != nil:
+		found = false
+	case !found:
+		// the ops rate is the only write a miss needs, debug checks run under the write lock
+		answered = cache.opsRate == nil && !cache.debugChecks
+	case cache.expired(node):
+		// removing it needs the write lock
+		found = false
+	default:
+		value = node.value
+		answered = cache.sharedHit(node)
+	}
+	if answered {
+		ok = found
+		cache.countGet(ok)
+	}
+	cache.mutex.RUnlock()
+
+	if !answered {
+		// a closure for the defers: a failed debug check must not leave the lock held
+		func() {
+			// protect DS
+			cache.mutex.Lock()
+			defer cache.mutex.Unlock()
+			defer cache.debugCheck()
+
+			if found {
+				ok = true
+				cache.countOp(opGet)
+				cache.countGet(true)
+				if cache.store[key] == node {
+					cache.hit(node)
+				}
+				return
+			}
+			// misses go through the regular path: the key may have been set or expired meanwhile
+			pending = cache.futures[key]
+			if pending == nil {
+				value, ok = cache.get(key)
+			}
+		}()
+	}
+
+	if pending != nil {
+		select {
+		case <-pending.done:
+			value, ok, err = pending.value, pending.err == nil, pending.err
+		case <-ctx.Done():
+			return value, false, ctx.Err()
+		}
+	}
+
+	// the hook may use the cache, it must run unlocked
+	if !ok && cache.onMiss != nil {
+		cache.onMiss(key)
+	}
+	return value, ok, err
+}
+
+// GetOrCompute returns the value of key, computing it with compute on a miss and storing it with
+// Set semantics. The lock is not held during compute: concurrent callers missing the same key wait
+// for the first one instead of computing it again, like the waiters of a future (see SetFuture).
+// If compute fails nothing is stored and every waiter gets the error
+func (cache *LruCache[K, V]) GetOrCompute(key K, compute func() (V, error)) (value V, err error) {
+	value, pending, computing := cache.getOrClaim(key)
+	if pending == nil {
+		return value, nil
+	}
+	if computing {
+		cache.resolveFuture(key, pending, compute)
+	}
+	<-pending.done
+	return pending.value, pending.err
+}
+
+// GetOrComputeCtx is GetOrCompute, waiting for the value until ctx is done. It returns ctx.Err()
+// if ctx is done first, without touching the cache when it is done already. The caller computing
+// the value runs compute in a new goroutine: giving up doesn't abandon the other waiters, the value
+// is still stored once computed. A panic in compute is returned to the waiters as an error.
+// A cache created by NewUnsafeCache can't be shared with that goroutine, compute runs inline there:
+// ctx isn't watched while it runs, and a panic reaches the caller as with GetOrCompute
+func (cache *LruCache[K, V]) GetOrComputeCtx(ctx context.Context, key K, compute func() (V, error)) (value V, err error) {
+	if err := ctx.Err(); err != nil {
+		return value, err
+	}
+
+	value, pending, computing := cache.getOrClaim(key)
+	if pending == nil {
+		return value, nil
+	}
+	if computing {
+		if cache.IsConcurrencySafe() {
+			go cache.resolveInBackground(key, pending, compute)
+		} else {
+			cache.resolveFuture(key, pending, compute)
+		}
+	}
+	select {
+	case <-pending.done:
+		return pending.value, pending.err
+	case <-ctx.Done():
+		return value, ctx.Err()
+	}
+}
+
+// getOrClaim returns the value of key if cached, otherwise the future to wait for. computing is
+// true when no future was pending: a new one was stored and the caller must resolve it
+func (cache *LruCache[K, V]) getOrClaim(key K) (value V, pending *future[V], computing bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	if pending = cache.futures[key]; pending != nil {
+		return value, pending, false
+	}
+	if value, ok := cache.get(key); ok {
+		return value, nil, false
+	}
+	return value, cache.addFuture(key), true
+}
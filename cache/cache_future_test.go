@@ -0,0 +1,375 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetFutureWaiters(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	cache.SetFuture("key1", func() (string, error) {
+		calls.Add(1)
+		<-release
+		return "computed", nil
+	})
+	// a second future for the same key is ignored while the first is pending
+	cache.SetFuture("key1", func() (string, error) {
+		calls.Add(1)
+		return "other", nil
+	})
+
+	const waiters = 10
+	var wg sync.WaitGroup
+	results := make(chan string, waiters)
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, ok := cache.Get("key1")
+			if !ok {
+				value = "miss"
+			}
+			results <- value
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for value := range results {
+		if value != "computed" {
+			t.Errorf("waiter got %s, want computed", value)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("resolve called %d times, want 1", calls.Load())
+	}
+
+	// the placeholder became a regular entry
+	if val, ok := cache.Get("key1"); !ok || val != "computed" {
+		t.Errorf("Get(key1) = (%v, %v), want (computed, true)", val, ok)
+	}
+	if len(cache.futures) != 0 {
+		t.Errorf("%d futures still pending", len(cache.futures))
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestSetFutureFailure(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "old")
+	release := make(chan struct{})
+	failure := errors.New("backend down")
+	cache.SetFuture("key1", func() (string, error) {
+		<-release
+		return "", failure
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := cache.GetCtx(context.Background(), "key1")
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if err := <-errs; !errors.Is(err, failure) {
+		t.Errorf("GetCtx error = %v, want %v", err, failure)
+	}
+	// the previous entry is visible again
+	if val, ok := cache.Get("key1"); !ok || val != "old" {
+		t.Errorf("Get(key1) = (%v, %v), want (old, true)", val, ok)
+	}
+	if len(cache.futures) != 0 {
+		t.Errorf("%d futures still pending", len(cache.futures))
+	}
+}
+
+func TestGetCtxCancelled(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	// a done context fails right away, without promoting anything
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok, err := cache.GetCtx(ctx, "key1"); ok || !errors.Is(err, context.Canceled) {
+		t.Errorf("GetCtx with cancelled context = (%v, %v), want (false, %v)", ok, err, context.Canceled)
+	}
+	if cache.head.key != "key2" {
+		t.Error("GetCtx with cancelled context should not promote")
+	}
+
+	// waiting on a future is bounded by the context
+	release := make(chan struct{})
+	defer close(release)
+	cache.SetFuture("slow", func() (string, error) {
+		<-release
+		return "value", nil
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok, err := cache.GetCtx(ctx, "slow"); ok || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetCtx on a slow future = (%v, %v), want (false, %v)", ok, err, context.DeadlineExceeded)
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "cached")
+
+	value, err := cache.GetOrCompute("key1", func() (string, error) {
+		t.Error("compute called for a cached key")
+		return "", nil
+	})
+	if err != nil || value != "cached" {
+		t.Errorf("GetOrCompute(key1) = (%v, %v), want (cached, nil)", value, err)
+	}
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	compute := func() (string, error) {
+		calls.Add(1)
+		<-release
+		return "computed", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.GetOrCompute("key2", compute)
+			if err != nil {
+				value = err.Error()
+			}
+			results <- value
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for value := range results {
+		if value != "computed" {
+			t.Errorf("caller got %s, want computed", value)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("compute called %d times, want 1", calls.Load())
+	}
+	if val, ok := cache.Peek("key2"); !ok || val != "computed" {
+		t.Errorf("Peek(key2) = (%v, %v), want (computed, true)", val, ok)
+	}
+}
+
+func TestGetOrComputeFailure(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	errBackend := errors.New("backend down")
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	const callers = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetOrCompute("key1", func() (string, error) {
+				calls.Add(1)
+				<-release
+				return "", errBackend
+			})
+			errs <- err
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if !errors.Is(err, errBackend) {
+			t.Errorf("caller got %v, want %v", err, errBackend)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("compute called %d times, want 1", calls.Load())
+	}
+	if _, ok := cache.Peek("key1"); ok {
+		t.Error("a failed compute should store nothing")
+	}
+
+	// the failure isn't cached either, the next call computes again
+	value, err := cache.GetOrCompute("key1", func() (string, error) { return "recovered", nil })
+	if err != nil || value != "recovered" {
+		t.Errorf("GetOrCompute(key1) = (%v, %v), want (recovered, nil)", value, err)
+	}
+}
+
+func TestGetOrComputeCtxCancelled(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+
+	// a done context fails right away: nothing computed, counted nor stored
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cache.GetOrComputeCtx(ctx, "key2", func() (string, error) {
+		t.Error("compute called with a cancelled context")
+		return "value2", nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("GetOrComputeCtx error = %v, want %v", err, context.Canceled)
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Stats() = %+v, a cancelled call should count nothing", stats)
+	}
+	if got := cache.Keys(); !reflect.DeepEqual(got, []string{"key1"}) {
+		t.Errorf("Keys() = %v, want [key1]", got)
+	}
+
+	// a waiter giving up doesn't stop the computation
+	release := make(chan struct{})
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = cache.GetOrComputeCtx(ctx, "slow", func() (string, error) {
+		<-release
+		return "computed", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOrComputeCtx error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+	value, err := cache.GetOrComputeCtx(context.Background(), "slow", func() (string, error) {
+		t.Error("compute called again while the first one was running")
+		return "", nil
+	})
+	if err != nil || value != "computed" {
+		t.Errorf("GetOrComputeCtx(slow) = (%v, %v), want (computed, nil)", value, err)
+	}
+}
+
+func TestGetOrComputePanic(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	release := make(chan struct{})
+
+	// a waiter joins the computation before it panics
+	waiterErr := make(chan error, 1)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		close(release)
+	}()
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		cache.GetOrCompute("key1", func() (string, error) {
+			<-release
+			panic("compute failed")
+		})
+	}()
+	go func() {
+		time.Sleep(time.Millisecond)
+		_, err := cache.GetOrCompute("key1", func() (string, error) { return "other", nil })
+		waiterErr <- err
+	}()
+
+	if p := <-panicked; p != "compute failed" {
+		t.Errorf("GetOrCompute recovered %v, want the panic of compute", p)
+	}
+	select {
+	case err := <-waiterErr:
+		// the waiter either got the failure, or came late and computed the value itself
+		if err != nil && !strings.Contains(err.Error(), "compute failed") {
+			t.Errorf("waiter got %v, want the panic reported as an error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the waiter is stuck on the future of a panicked compute")
+	}
+
+	// the key isn't wedged: reads and new computations go through
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Get("key2")
+		value, err := cache.GetOrCompute("key3", func() (string, error) { return "computed", nil })
+		if err != nil || value != "computed" {
+			t.Errorf("GetOrCompute(key3) = (%v, %v), want (computed, nil)", value, err)
+		}
+		cache.Get("key1")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the key of a panicked compute blocks later operations")
+	}
+	if len(cache.futures) != 0 {
+		t.Errorf("%d futures still pending", len(cache.futures))
+	}
+}
+
+func TestSetFuturePanicAndUnsafe(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	if err := cache.SetFuture("key1", func() (string, error) { panic("resolve failed") }); err != nil {
+		t.Fatalf("SetFuture() error = %v", err)
+	}
+
+	// the panic reaches the waiters as an error, not the program
+	_, ok, err := cache.GetCtx(context.Background(), "key1")
+	if ok {
+		t.Error("Get on a panicked future should miss")
+	}
+	if err != nil && !strings.Contains(err.Error(), "resolve failed") {
+		t.Errorf("GetCtx() error = %v, want the panic reported as an error", err)
+	}
+	if !waitFor(time.Second, func() bool { return !cache.Contains("key1") && cache.Len() == 0 }) {
+		t.Error("the panicked future should leave the cache empty")
+	}
+
+	unsafe, _ := NewUnsafeCache[string, string](5)
+	if err := unsafe.SetFuture("key1", func() (string, error) { return "value", nil }); err == nil {
+		t.Error("SetFuture on an unsafe cache should fail")
+	}
+	if _, ok := unsafe.Get("key1"); ok {
+		t.Error("a refused future should store nothing")
+	}
+}
+
+func TestGetOrComputeCtxPanicAndUnsafe(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	_, err := cache.GetOrComputeCtx(context.Background(), "key1", func() (string, error) { panic("compute failed") })
+	if err == nil || !strings.Contains(err.Error(), "compute failed") {
+		t.Errorf("GetOrComputeCtx() error = %v, want the panic reported as an error", err)
+	}
+	if value, err := cache.GetOrComputeCtx(context.Background(), "key1", func() (string, error) { return "computed", nil }); err != nil || value != "computed" {
+		t.Errorf("GetOrComputeCtx() after a panic = (%v, %v), want (computed, nil)", value, err)
+	}
+
+	// an unsafe cache computes inline, no goroutine touches it
+	unsafe, _ := NewUnsafeCache[string, string](5)
+	value, err := unsafe.GetOrComputeCtx(context.Background(), "key1", func() (string, error) { return "computed", nil })
+	if err != nil || value != "computed" {
+		t.Errorf("unsafe GetOrComputeCtx() = (%v, %v), want (computed, nil)", value, err)
+	}
+	if value, ok := unsafe.Peek("key1"); !ok || value != "computed" {
+		t.Errorf("unsafe Peek(key1) = (%v, %v), want (computed, true)", value, ok)
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import "unsafe"
+
+// mapEntryOverhead approximates what a map entry costs on top of its key and value:
+// a tophash byte, rounded up, and the buckets left empty by the map load factor (6.5 of 8 slots)
+const mapEntryOverhead = 8
+
+// EstimatedHeapBytes approximates the heap memory used by the cache: the cache struct, a node per entry,
+// the map entry indexing it, and the bytes of string or []byte keys and values.
+// It is an estimate: allocator rounding, map growth, the side structures of optional features
+// (dependencies, indexes, histories...) and the memory other keys and values point to are not
+// accounted for, and interned values are counted once per entry
+func (cache *LruCache[K, V]) EstimatedHeapBytes() int64 {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	var (
+		node  cacheNode[K, V]
+		key   K
+		value *cacheNode[K, V]
+	)
+	perEntry := int64(unsafe.Sizeof(node)) +
+		int64(unsafe.Sizeof(key)+unsafe.Sizeof(value)) + mapEntryOverhead
+
+	total := int64(unsafe.Sizeof(*cache))
+	for key, node := range cache.store {
+		// the node and the map share the bytes of the key
+		total += perEntry + int64(contentBytes(key)+contentBytes(node.value))
+	}
+	return total
+}
+
+// contentBytes returns the length of the bytes a string or a []byte points to, 0 for other types
+func contentBytes(value any) int {
+	switch value := value.(type) {
+	case string:
+		return len(value)
+	case []byte:
+		return len(value)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+)
+
+// Unique keys are estimated with a HyperLogLog sketch: every key is hashed, the first bits of the
+// hash pick a register which keeps the longest run of leading zeros seen in the remaining bits.
+// Long runs are rare, so the registers tell how many distinct hashes were seen, in a fixed 16KiB
+// whatever the number of keys. With 2^14 registers the standard error is about 0.8%
+
+const hllPrecision = 14
+
+type hyperLogLog struct {
+	seed      maphash.Seed
+	registers [1 << hllPrecision]uint8
+}
+
+// WithUniqueKeyEstimation enables EstimatedUniqueKeys by feeding every Set key to a HyperLogLog sketch
+func WithUniqueKeyEstimation() Option {
+	return func(o *options) {
+		o.uniqueKeyEstimation = true
+	}
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{seed: maphash.MakeSeed()}
+}
+
+func (sketch *hyperLogLog) add(key string) {
+	hash := maphash.String(sketch.seed, key)
+	register := hash >> (64 - hllPrecision)
+	// the sentinel bit bounds the run of zeros when the remaining bits are all zeros
+	rest := hash<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(bits.LeadingZeros64(rest) + 1)
+	if rank > sketch.registers[register] {
+		sketch.registers[register] = rank
+	}
+}
+
+func (sketch *hyperLogLog) estimate() uint64 {
+	const registers = float64(len(sketch.registers))
+	sum, zeros := 0.0, 0
+	for _, rank := range sketch.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/registers)
+	estimate := alpha * registers * registers / sum
+	// small cardinalities are better estimated by counting the registers still empty
+	if estimate <= 2.5*registers && zeros > 0 {
+		estimate = registers * math.Log(registers/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+// trackUniqueKey feeds key to the unique keys sketch.
+// Keys other than strings are hashed through their Go-syntax representation
+func (cache *LruCache[K, V]) trackUniqueKey(key K) {
+	if cache.uniqueKeys == nil {
+		return
+	}
+	if s, ok := any(key).(string); ok {
+		cache.uniqueKeys.add(s)
+	} else {
+		cache.uniqueKeys.add(fmt.Sprintf("%#v", key))
+	}
+}
+
+// EstimatedUniqueKeys estimates how many distinct keys were ever Set, evicted ones included.
+// Compared with Len it tells the churn of the keyspace from the size of the working set.
+// It always returns 0 unless the cache was created WithUniqueKeyEstimation
+func (cache *LruCache[K, V]) EstimatedUniqueKeys() uint64 {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.uniqueKeys == nil {
+		return 0
+	}
+	return cache.uniqueKeys.estimate()
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestEstimatedUniqueKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		unique  int
+		repeats int
+	}{
+		{"small", 1000, 5},
+		{"large", 200000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := NewStringCache(100, WithUniqueKeyEstimation())
+			for r := 0; r < tt.repeats; r++ {
+				for i := 0; i < tt.unique; i++ {
+					cache.Set(fmt.Sprintf("key%d", i), "value")
+				}
+			}
+
+			// 4% is 5 times the standard error of the sketch
+			got := cache.EstimatedUniqueKeys()
+			if relErr := math.Abs(float64(got)-float64(tt.unique)) / float64(tt.unique); relErr > 0.04 {
+				t.Errorf("EstimatedUniqueKeys() = %d, want %d within 4%%", got, tt.unique)
+			}
+		})
+	}
+
+	disabled, _ := NewStringCache(100)
+	disabled.Set("key", "value")
+	if got := disabled.EstimatedUniqueKeys(); got != 0 {
+		t.Errorf("EstimatedUniqueKeys() without estimation = %d, want 0", got)
+	}
+}
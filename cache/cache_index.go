@@ -0,0 +1,58 @@
+package cache
+
+// The secondary index maps a field extracted from the values to the key holding that value.
+// It is maintained along the store: indexed on insertion and update, unindexed on update and removal.
+// Collisions are resolved by last-writer-wins: the last key set with a given field owns it, and the
+// entry it replaced in the index is no longer reachable through it, even once the winner is gone
+
+type secondaryIndex[K comparable, V any] struct {
+	extract func(value V) string
+	keys    map[string]K
+}
+
+// WithSecondaryIndex maintains an index from extract(value) to the key of the entry,
+// queried with GetByIndex
+func WithSecondaryIndex[V any](extract func(value V) string) Option {
+	return func(o *options) {
+		o.indexExtract = extract
+	}
+}
+
+// indexNode makes node reachable through the index
+func (cache *LruCache[K, V]) indexNode(node *cacheNode[K, V]) {
+	if cache.index != nil {
+		cache.index.keys[cache.index.extract(node.value)] = node.key
+	}
+}
+
+// unindexNode removes node from the index, unless another key took its index entry over
+func (cache *LruCache[K, V]) unindexNode(node *cacheNode[K, V]) {
+	if cache.index == nil {
+		return
+	}
+	indexKey := cache.index.extract(node.value)
+	if cache.index.keys[indexKey] == node.key {
+		delete(cache.index.keys, indexKey)
+	}
+}
+
+// GetByIndex looks an entry up by the field extracted from its value, see WithSecondaryIndex.
+// It behaves like Get on the key found, which is returned along with the value
+func (cache *LruCache[K, V]) GetByIndex(indexKey string) (primaryKey K, value V, ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.index == nil {
+		return primaryKey, value, false
+	}
+	primaryKey, ok = cache.index.keys[indexKey]
+	if !ok {
+		return primaryKey, value, false
+	}
+	if value, ok = cache.get(primaryKey); !ok {
+		var noKey K
+		return noKey, value, false
+	}
+	return primaryKey, value, true
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+// emailOf extracts the email of values formatted as "name|email"
+func emailOf(value string) string {
+	return strings.SplitN(value, "|", 2)[1]
+}
+
+func TestSecondaryIndex(t *testing.T) {
+	cache, _ := NewStringCache(3, WithSecondaryIndex(emailOf))
+	cache.Set("user1", "alice|alice@example.com")
+	cache.Set("user2", "bob|bob@example.com")
+
+	lookups := []struct {
+		name      string
+		indexKey  string
+		wantKey   string
+		wantValue string
+		wantOk    bool
+	}{
+		{"first entry", "alice@example.com", "user1", "alice|alice@example.com", true},
+		{"second entry", "bob@example.com", "user2", "bob|bob@example.com", true},
+		{"unknown", "carol@example.com", "", "", false},
+	}
+	for _, tt := range lookups {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, ok := cache.GetByIndex(tt.indexKey)
+			if key != tt.wantKey || value != tt.wantValue || ok != tt.wantOk {
+				t.Errorf("GetByIndex(%s) = (%v, %v, %v), want (%v, %v, %v)",
+					tt.indexKey, key, value, ok, tt.wantKey, tt.wantValue, tt.wantOk)
+			}
+		})
+	}
+
+	// updating a value moves its index entry
+	cache.Set("user1", "alice|alice@work.example.com")
+	if _, _, ok := cache.GetByIndex("alice@example.com"); ok {
+		t.Error("the old email should no longer be indexed")
+	}
+	if key, _, ok := cache.GetByIndex("alice@work.example.com"); !ok || key != "user1" {
+		t.Errorf("GetByIndex(alice@work.example.com) = (%v, %v), want (user1, true)", key, ok)
+	}
+
+	// user2 is the LRU, evicting it drops its index entry
+	cache.Set("user3", "carol|carol@example.com")
+	cache.Set("user4", "dave|dave@example.com")
+	if _, _, ok := cache.GetByIndex("bob@example.com"); ok {
+		t.Error("an evicted entry should no longer be indexed")
+	}
+	if len(cache.index.keys) != 3 {
+		t.Errorf("index holds %d entries, want 3", len(cache.index.keys))
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestSecondaryIndexCollision(t *testing.T) {
+	cache, _ := NewStringCache(5, WithSecondaryIndex(emailOf))
+	cache.Set("user1", "alice|shared@example.com")
+	cache.Set("user2", "bob|shared@example.com")
+
+	// last writer wins
+	if key, _, ok := cache.GetByIndex("shared@example.com"); !ok || key != "user2" {
+		t.Errorf("GetByIndex(shared@example.com) = (%v, %v), want (user2, true)", key, ok)
+	}
+
+	// removing the loser leaves the winner indexed
+	cache.Delete("user1")
+	if key, _, ok := cache.GetByIndex("shared@example.com"); !ok || key != "user2" {
+		t.Errorf("GetByIndex after deleting user1 = (%v, %v), want (user2, true)", key, ok)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestSecondaryIndexDisabled(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("user1", "alice|alice@example.com")
+	if _, _, ok := cache.GetByIndex("alice@example.com"); ok {
+		t.Error("GetByIndex without an index should miss")
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+import "sort"
+
+// The DLL only knows about access order, so every node also carries the sequence number it was
+// inserted with. Sequence numbers only grow, ordering entries by them gives the insertion order.
+// Whether an update counts as an insertion is decided at construction, see WithInsertionRefreshOnUpdate
+
+// InsertionOrder returns the entries ordered from the oldest insertion to the newest,
+// regardless of how they were accessed since. Expired entries are left out, but not removed
+func (cache *LruCache[K, V]) InsertionOrder() []Entry[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	nodes := make([]*cacheNode[K, V], 0, len(cache.store))
+	for _, node := range cache.store {
+		if !cache.expired(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].seq < nodes[j].seq
+	})
+
+	entries := make([]Entry[K, V], len(nodes))
+	for i, node := range nodes {
+		entries[i] = Entry[K, V]{Key: node.key, Value: node.value}
+	}
+	return entries
+}
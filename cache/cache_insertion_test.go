@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInsertionOrder(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+
+	// shuffle the access order
+	cache.Get("key1")
+	cache.Get("key2")
+	cache.Set("key1", "value1-updated")
+
+	want := []Entry[string, string]{{"key1", "value1-updated"}, {"key2", "value2"}, {"key3", "value3"}}
+	if got := cache.InsertionOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertionOrder() = %v, want %v", got, want)
+	}
+
+	// a re-inserted key goes last
+	cache.Delete("key2")
+	cache.Set("key2", "value2-again")
+	want = []Entry[string, string]{{"key1", "value1-updated"}, {"key3", "value3"}, {"key2", "value2-again"}}
+	if got := cache.InsertionOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertionOrder() after re-insert = %v, want %v", got, want)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestInsertionOrderRefreshOnUpdate(t *testing.T) {
+	cache, _ := NewStringCache(5, WithInsertionRefreshOnUpdate())
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+	cache.Get("key3")
+	cache.Set("key1", "value1-updated")
+
+	want := []Entry[string, string]{{"key2", "value2"}, {"key3", "value3"}, {"key1", "value1-updated"}}
+	if got := cache.InsertionOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertionOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestInsertionOrderEmpty(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	if got := cache.InsertionOrder(); got == nil || len(got) != 0 {
+		t.Errorf("InsertionOrder() on empty cache = %#v, want empty slice", got)
+	}
+}
+
+func TestInsertionOrderSkipsExpired(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now))
+	cache.Set("key1", "value1")
+	cache.SetWithTTL("key2", "value2", time.Second)
+	cache.Set("key3", "value3")
+	clock.Advance(2 * time.Second)
+
+	want := []Entry[string, string]{{"key1", "value1"}, {"key3", "value3"}}
+	if got := cache.InsertionOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertionOrder() = %v, want %v", got, want)
+	}
+}
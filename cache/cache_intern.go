@@ -0,0 +1,66 @@
+package cache
+
+// String interning makes entries holding equal values share a single backing string.
+// The intern table maps a value to its canonical copy along with the number of entries using it,
+// the canonical copy is forgotten as soon as no entry references it anymore, so the table never
+// holds more values than the cache does
+
+type internedValue struct {
+	value string
+	refs  int
+}
+
+type internTable struct {
+	values map[string]*internedValue
+}
+
+// WithStringInterning stores equal values only once, which saves memory when many keys hold the same value.
+// It requires string values, NewCache fails otherwise
+func WithStringInterning() Option {
+	return func(o *options) {
+		o.internValues = true
+	}
+}
+
+func newInternTable() *internTable {
+	return &internTable{values: make(map[string]*internedValue)}
+}
+
+// intern returns the canonical copy of value and takes a reference on it
+func (table *internTable) intern(value string) string {
+	interned, ok := table.values[value]
+	if !ok {
+		interned = &internedValue{value: value}
+		table.values[value] = interned
+	}
+	interned.refs++
+	return interned.value
+}
+
+// release drops a reference on value
+func (table *internTable) release(value string) {
+	interned, ok := table.values[value]
+	if !ok {
+		return
+	}
+	interned.refs--
+	if interned.refs == 0 {
+		delete(table.values, value)
+	}
+}
+
+// internValue returns the value to store for value, interned if enabled
+func (cache *LruCache[K, V]) internValue(value V) V {
+	if cache.interned == nil {
+		return value
+	}
+	// interning is only enabled for string values, see newCache
+	return any(cache.interned.intern(any(value).(string))).(V)
+}
+
+// releaseValue is called when an entry stops holding value
+func (cache *LruCache[K, V]) releaseValue(value V) {
+	if cache.interned != nil {
+		cache.interned.release(any(value).(string))
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// sameBacking reports whether two strings share their backing bytes
+func sameBacking(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+func TestStringInterning(t *testing.T) {
+	cache, _ := NewStringCache(10, WithStringInterning())
+
+	// every value is built separately, so they would not share memory without interning
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), strings.Repeat("shared", 10))
+	}
+	cache.Set("other", strings.Repeat("other", 10))
+
+	first := cache.store["key0"].value
+	for i := 1; i < 5; i++ {
+		if !sameBacking(first, cache.store[fmt.Sprintf("key%d", i)].value) {
+			t.Errorf("key%d doesn't share the interned value", i)
+		}
+	}
+	if len(cache.interned.values) != 2 {
+		t.Errorf("intern table holds %d values, want 2", len(cache.interned.values))
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestStringInterningRelease(t *testing.T) {
+	cache, _ := NewStringCache(3, WithStringInterning())
+	cache.Set("key1", "shared")
+	cache.Set("key2", "shared")
+	cache.Set("key3", "unique")
+
+	// updates release the previous value
+	cache.Set("key3", "shared")
+	if _, ok := cache.interned.values["unique"]; ok {
+		t.Error("unique should be released once no entry holds it")
+	}
+
+	// so do evictions and deletions
+	cache.Set("key4", "new")
+	cache.Delete("key2")
+	cache.Delete("key3")
+	if _, ok := cache.interned.values["shared"]; ok {
+		t.Error("shared should be released once no entry holds it")
+	}
+	if len(cache.interned.values) != 1 {
+		t.Errorf("intern table holds %d values, want 1", len(cache.interned.values))
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	// a clone gets its own table
+	clone := cache.Clone()
+	clone.Delete("key4")
+	if _, ok := cache.interned.values["new"]; !ok {
+		t.Error("deleting from the clone should not release values of the original")
+	}
+	if err := verifyIntegrity(clone); err != nil {
+		t.Errorf("integrity check failed on the clone: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// The janitor makes expiration proactive: a background goroutine sweeps the DLL on a fixed
+// interval and removes the entries past their TTL or idle window, so that dead entries don't hold
+// slots (and push live entries out) until an access happens to find them. A sweep walks every
+// entry under the lock, the interval trades freed slots for lock hold time
+
+type janitor struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartJanitor starts sweeping expired entries every interval, replacing the janitor already
+// running if any. It returns an error if interval is not positive, or for a cache created by
+// NewUnsafeCache: the sweeps would race with its owner
+func (cache *LruCache[K, V]) StartJanitor(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("janitor interval must be greater than 0, got %v", interval)
+	}
+	if !cache.IsConcurrencySafe() {
+		return fmt.Errorf("the janitor requires a concurrency safe cache")
+	}
+	started := &janitor{stop: make(chan struct{}), done: make(chan struct{})}
+
+	// protect DS
+	cache.mutex.Lock()
+	previous := cache.janitor
+	cache.janitor = started
+	cache.mutex.Unlock()
+
+	// the previous sweep may be waiting for the lock, it must be stopped unlocked
+	previous.halt()
+	go cache.runJanitor(started, interval)
+	return nil
+}
+
+// StopJanitor stops the janitor and waits for its goroutine to exit.
+// It does nothing if no janitor is running, calling it twice is safe
+func (cache *LruCache[K, V]) StopJanitor() {
+	// protect DS
+	cache.mutex.Lock()
+	running := cache.janitor
+	cache.janitor = nil
+	cache.mutex.Unlock()
+
+	running.halt()
+}
+
+// halt stops the janitor goroutine and waits for it, a nil janitor is not running
+func (j *janitor) halt() {
+	if j == nil {
+		return
+	}
+	close(j.stop)
+	<-j.done
+}
+
+func (cache *LruCache[K, V]) runJanitor(j *janitor, interval time.Duration) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cache.sweep()
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// sweep removes every expired entry, it returns how many entries were removed
+func (cache *LruCache[K, V]) sweep() (removed int) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	for _, node := range cache.nodes() {
+		// already removed as the dependent of an expired entry
+		if cache.store[node.key] != node {
+			continue
+		}
+		if cache.expired(node) {
+			removed += 1 + cache.discard(node, ReasonExpired)
+		}
+	}
+	return removed
+}
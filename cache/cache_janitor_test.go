@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestJanitorSweepsExpiredEntries(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now))
+	for i := 1; i <= 3; i++ {
+		cache.SetWithTTL(fmt.Sprintf("key%d", i), "value", time.Second)
+	}
+	cache.Set("forever", "value")
+
+	if err := cache.StartJanitor(0); err == nil {
+		t.Error("expected an error for an interval of 0")
+	}
+	if err := cache.StartJanitor(time.Millisecond); err != nil {
+		t.Fatalf("StartJanitor failed: %v", err)
+	}
+	defer cache.StopJanitor()
+
+	clock.Advance(2 * time.Second)
+	if !waitFor(time.Second, func() bool { return cache.Len() == 1 }) {
+		t.Fatalf("Len() = %d, the janitor should have removed the expired entries", cache.Len())
+	}
+	if !cache.Contains("forever") {
+		t.Error("the entry without TTL should have been kept")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestJanitorRestart(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now))
+	goroutines := runtime.NumGoroutine()
+
+	cache.StartJanitor(time.Millisecond)
+	// replacing a running janitor stops the previous one
+	cache.StartJanitor(time.Millisecond)
+	cache.StopJanitor()
+	cache.StopJanitor()
+
+	cache.StartJanitor(time.Millisecond)
+	cache.SetWithTTL("key1", "value", time.Second)
+	clock.Advance(2 * time.Second)
+	if !waitFor(time.Second, func() bool { return cache.Len() == 0 }) {
+		t.Errorf("Len() = %d, the restarted janitor should have removed key1", cache.Len())
+	}
+	cache.Close()
+
+	if !waitFor(time.Second, func() bool { return runtime.NumGoroutine() <= goroutines }) {
+		t.Errorf("%d goroutines running, %d before the janitors started", runtime.NumGoroutine(), goroutines)
+	}
+}
+
+func TestJanitorInvalid(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	if err := cache.StartJanitor(0); err == nil {
+		t.Error("StartJanitor(0) should fail")
+	}
+
+	unsafe, _ := NewUnsafeCache[string, string](5)
+	if err := unsafe.StartJanitor(time.Millisecond); err == nil {
+		unsafe.StopJanitor()
+		t.Error("StartJanitor on an unsafe cache should fail")
+	}
+}
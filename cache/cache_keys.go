@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The key list format is a uvarint count of keys, then every key as a uvarint length followed by
+// its bytes. Keys are opaque bytes: any string, valid UTF-8 or not, survives the round trip
+
+// MarshalKeys writes the keys of the cache, from MRU to LRU, without their values.
+// The keys are copied under the lock and written after releasing it, a slow writer doesn't block the cache.
+// Only caches with string keys can be marshaled
+func MarshalKeys[V any](cache *LruCache[string, V], w io.Writer) error {
+	// protect DS
+	cache.mutex.Lock()
+	keys := make([]string, 0, len(cache.store))
+	for _, node := range cache.nodes() {
+		if !cache.expired(node) {
+			keys = append(keys, node.key)
+		}
+	}
+	cache.mutex.Unlock()
+
+	bw := bufio.NewWriter(w)
+	var length [binary.MaxVarintLen64]byte
+	bw.Write(length[:binary.PutUvarint(length[:], uint64(len(keys)))])
+	for _, key := range keys {
+		bw.Write(length[:binary.PutUvarint(length[:], uint64(len(key)))])
+		bw.WriteString(key)
+	}
+	// a bufio.Writer keeps the first error, returned here
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing keys: %w", err)
+	}
+	return nil
+}
+
+// UnmarshalKeys reads a key list written by MarshalKeys, in the same order
+func UnmarshalKeys(r io.Reader) ([]string, error) {
+	br := bufio.NewReader(r)
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading key count: %w", err)
+	}
+
+	// the count isn't trusted for the allocation, a corrupted one would be huge
+	keys := make([]string, 0, min(count, 1024))
+	for i := uint64(0); i < count; i++ {
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading length of key %d: %w", i, err)
+		}
+		var builder strings.Builder
+		if _, err := io.CopyN(&builder, br, int64(length)); err != nil {
+			return nil, fmt.Errorf("reading key %d: %w", i, err)
+		}
+		keys = append(keys, builder.String())
+	}
+	return keys, nil
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalKeysRoundTrip(t *testing.T) {
+	cache, _ := NewStringCache(1000)
+	for i := 0; i < 990; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	// keys that are not text, or too long for a single length byte
+	for _, key := range []string{"", "\x00\xff\xfe", "line\nbreak", "é", strings.Repeat("k", 300)} {
+		cache.Set(key, "value")
+	}
+	cache.Get("key0")
+
+	var buf bytes.Buffer
+	if err := MarshalKeys(cache, &buf); err != nil {
+		t.Fatalf("MarshalKeys() error = %v", err)
+	}
+	keys, err := UnmarshalKeys(&buf)
+	if err != nil {
+		t.Fatalf("UnmarshalKeys() error = %v", err)
+	}
+	if want := listKeys(cache); !reflect.DeepEqual(keys, want) {
+		t.Errorf("UnmarshalKeys() returned %d keys not matching the %d keys from MRU to LRU", len(keys), len(want))
+	}
+}
+
+func TestUnmarshalKeysTruncated(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	var buf bytes.Buffer
+	MarshalKeys(cache, &buf)
+	truncated := buf.Bytes()[:buf.Len()-2]
+	if _, err := UnmarshalKeys(bytes.NewReader(truncated)); !errors.Is(err, io.EOF) {
+		t.Errorf("UnmarshalKeys() of truncated input error = %v, want %v", err, io.EOF)
+	}
+
+	empty, _ := NewStringCache(5)
+	buf.Reset()
+	MarshalKeys(empty, &buf)
+	if keys, err := UnmarshalKeys(&buf); err != nil || len(keys) != 0 {
+		t.Errorf("UnmarshalKeys() of empty cache = (%v, %v), want no keys", keys, err)
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Every operation runs under the cache-wide mutex, so a slow one (a slow comparator passed to
+// Reorder, a huge Checkpoint...) delays all the others. Lock hold tracking wraps the mutex of the
+// cache to time each critical section, from acquisition to release, and keeps the longest one.
+// Only exclusive holds are timed: readers run concurrently, they don't delay each other
+
+type lockHoldTracker struct {
+	rwLocker
+	now func() time.Time
+	// only accessed while the lock is held
+	acquiredAt time.Time
+	// longest hold, in nanoseconds
+	max atomic.Int64
+}
+
+// WithLockHoldTracking enables MaxLockHoldTime by timing how long every operation holds the lock
+func WithLockHoldTracking() Option {
+	return func(o *options) {
+		o.lockHoldTracking = true
+	}
+}
+
+func (tracker *lockHoldTracker) Lock() {
+	tracker.rwLocker.Lock()
+	tracker.acquiredAt = tracker.now()
+}
+
+func (tracker *lockHoldTracker) Unlock() {
+	held := int64(tracker.now().Sub(tracker.acquiredAt))
+	for {
+		longest := tracker.max.Load()
+		if held <= longest || tracker.max.CompareAndSwap(longest, held) {
+			break
+		}
+	}
+	tracker.rwLocker.Unlock()
+}
+
+// MaxLockHoldTime returns the longest time an operation held the lock of the cache since it was
+// created or since the last ResetMaxLockHoldTime.
+// It always returns 0 unless the cache was created WithLockHoldTracking
+func (cache *LruCache[K, V]) MaxLockHoldTime() time.Duration {
+	if tracker, ok := cache.mutex.(*lockHoldTracker); ok {
+		return time.Duration(tracker.max.Load())
+	}
+	return 0
+}
+
+// ResetMaxLockHoldTime starts measuring the longest lock hold over again
+func (cache *LruCache[K, V]) ResetMaxLockHoldTime() {
+	if tracker, ok := cache.mutex.(*lockHoldTracker); ok {
+		tracker.max.Store(0)
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxLockHoldTime(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now), WithLockHoldTracking())
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+	if got := cache.MaxLockHoldTime(); got != 0 {
+		t.Errorf("MaxLockHoldTime() with a still clock = %v, want 0", got)
+	}
+
+	// every comparison takes a second, all of them under the lock
+	comparisons := 0
+	cache.Reorder(func(a, b Entry[string, string]) bool {
+		comparisons++
+		clock.Advance(time.Second)
+		return a.Key < b.Key
+	})
+	if got, want := cache.MaxLockHoldTime(), time.Duration(comparisons)*time.Second; got != want {
+		t.Errorf("MaxLockHoldTime() after a slow Reorder = %v, want %v", got, want)
+	}
+
+	// quick operations don't lower the maximum
+	cache.Get("key1")
+	if got := cache.MaxLockHoldTime(); got < time.Second {
+		t.Errorf("MaxLockHoldTime() after a quick Get = %v, want the slow Reorder", got)
+	}
+
+	cache.ResetMaxLockHoldTime()
+	if got := cache.MaxLockHoldTime(); got != 0 {
+		t.Errorf("MaxLockHoldTime() after reset = %v, want 0", got)
+	}
+
+	// the wrapped mutex still tells safe and unsafe caches apart, clones are tracked too
+	if !cache.IsConcurrencySafe() || !cache.Clone().IsConcurrencySafe() {
+		t.Error("tracked cache should be concurrency safe")
+	}
+	unsafe, _ := NewUnsafeCache[string, string](5, WithLockHoldTracking())
+	if unsafe.IsConcurrencySafe() {
+		t.Error("tracked unsafe cache should not be concurrency safe")
+	}
+	if _, ok := cache.Clone().mutex.(*lockHoldTracker); !ok {
+		t.Error("clone of a tracked cache should be tracked")
+	}
+}
+
+func TestMaxLockHoldTimeDisabled(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	if got := cache.MaxLockHoldTime(); got != 0 {
+		t.Errorf("MaxLockHoldTime() without tracking = %v, want 0", got)
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+// Capacity evictions happen deep inside set, with the lock held. Calling the hook from there would
+// deadlock as soon as it uses the cache, so evicted entries are queued under the lock instead and
+// handed to the hook once the operation releases it, see unlockAndNotify
+
+// WithOnEvict registers a hook called with the key and value of every entry evicted for capacity.
+// Deletions, updates, expirations and cascades don't call it.
+// It runs after the lock is released, in the goroutine of the operation that caused the eviction,
+// and may call the cache.
+// K and V must match the key and value types of the cache, NewCache returns an error otherwise
+func WithOnEvict[K comparable, V any](onEvict func(key K, value V)) Option {
+	return func(o *options) {
+		o.onEvict = onEvict
+	}
+}
+
+// queueEviction keeps the entry of node for the hook, or for SetWithEviction, if the removal was
+// a capacity eviction
+func (cache *LruCache[K, V]) queueEviction(node *cacheNode[K, V], reason RemovalReason) {
+	if (cache.onEvict != nil || cache.capturingEvictions) && reason == ReasonEvicted {
+		cache.evicted = append(cache.evicted, Entry[K, V]{Key: node.key, Value: node.value})
+	}
+}
+
+// unlockAndNotify releases the lock, then calls the WithOnEvict hook on the entries evicted
+// while it was held. Operations that may evict use it in place of a plain Unlock
+func (cache *LruCache[K, V]) unlockAndNotify() {
+	evicted := cache.evicted
+	cache.evicted = nil
+	cache.mutex.Unlock()
+
+	for _, entry := range evicted {
+		cache.onEvict(entry.Key, entry.Value)
+	}
+}
+
+// SetWithEviction is Set, also returning the entry it evicted to make room, if any: evicted is
+// false for an update or while the cache had room. Should a Set evict several entries (a byte
+// budget, see NewSizeCache) the least recently used one is returned.
+// It is the synchronous counterpart of WithOnEvict, which is still called when registered
+func (cache *LruCache[K, V]) SetWithEviction(key K, value V) (updated bool, victim Entry[K, V], evicted bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	queued := len(cache.evicted)
+	cache.capturingEvictions = true
+	updated = cache.set(key, value)
+	cache.capturingEvictions = false
+
+	if len(cache.evicted) > queued {
+		victim, evicted = cache.evicted[queued], true
+	}
+	// without a hook nothing else wants them
+	if cache.onEvict == nil {
+		cache.evicted = cache.evicted[:queued]
+	}
+	return updated, victim, evicted
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnEvict(t *testing.T) {
+	var evicted []Entry[string, string]
+	var cache *StringCache
+	cache, _ = NewStringCache(2, WithOnEvict(func(key, value string) {
+		evicted = append(evicted, Entry[string, string]{key, value})
+		// the lock is released, using the cache doesn't deadlock
+		cache.Len()
+	}))
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key2", "updated") // updates don't evict
+	cache.Delete("key1")         // nor do deletions
+	cache.Set("key3", "value3")
+	cache.Set("key4", "value4")
+	cache.SetWithDeps("key5", "value5", nil)
+
+	want := []Entry[string, string]{{"key2", "updated"}, {"key3", "value3"}}
+	if !reflect.DeepEqual(evicted, want) {
+		t.Errorf("evicted entries = %v, want %v", evicted, want)
+	}
+
+	// evictions deferred by SuspendEviction are reported when they happen
+	evicted = nil
+	cache.SuspendEviction()
+	cache.Set("key6", "value6")
+	if len(evicted) != 0 {
+		t.Errorf("evicted entries while suspended = %v, want none", evicted)
+	}
+	cache.ResumeEviction()
+	if want := []Entry[string, string]{{"key4", "value4"}}; !reflect.DeepEqual(evicted, want) {
+		t.Errorf("evicted entries on resume = %v, want %v", evicted, want)
+	}
+}
+
+func TestSetWithEviction(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	for _, key := range []string{"key1", "key2"} {
+		if _, _, evicted := cache.SetWithEviction(key, "value"); evicted {
+			t.Errorf("SetWithEviction(%s) evicted while the cache had room", key)
+		}
+	}
+	if updated, _, evicted := cache.SetWithEviction("key1", "updated"); !updated || evicted {
+		t.Errorf("update = (%v, %v), want an update without eviction", updated, evicted)
+	}
+
+	// key2 is now the tail
+	updated, victim, evicted := cache.SetWithEviction("key3", "value3")
+	want := Entry[string, string]{Key: "key2", Value: "value"}
+	if updated || !evicted || victim != want {
+		t.Errorf("SetWithEviction(key3) = (%v, %v, %v), want (false, %v, true)", updated, victim, evicted, want)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	// a registered hook still sees the eviction
+	var hooked []string
+	withHook, _ := NewStringCache(1, WithOnEvict(func(key, value string) { hooked = append(hooked, key) }))
+	withHook.Set("key1", "value1")
+	if _, victim, _ := withHook.SetWithEviction("key2", "value2"); victim.Key != "key1" {
+		t.Errorf("victim = %v, want key1", victim.Key)
+	}
+	if !reflect.DeepEqual(hooked, []string{"key1"}) {
+		t.Errorf("hook saw %v, want [key1]", hooked)
+	}
+}
+
+func TestOnEvictTypes(t *testing.T) {
+	var evicted []int
+	cache, err := NewCache[int, float64](1, WithOnEvict(func(key int, value float64) {
+		evicted = append(evicted, key)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	cache.Set(1, 1.5)
+	cache.Set(2, 2.5)
+	if !reflect.DeepEqual(evicted, []int{1}) {
+		t.Errorf("evicted keys = %v, want [1]", evicted)
+	}
+
+	if _, err := NewCache[int, float64](1, WithOnEvict(func(key, value string) {})); err == nil {
+		t.Error("NewCache[int, float64] should reject a WithOnEvict hook taking strings")
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import "fmt"
+
+// The eviction policy decides how the DLL is ordered and which entry makes room for a new one.
+// LRU and FIFO both evict the tail: they differ only in whether a Get moves its entry to the head.
+// LFU counts the accesses of every entry and evicts the least frequently used, ties broken by
+// recency or by insertion order (see WithLFUTieBreak); finding it walks the DLL from the tail, so
+// eviction costs O(n) under LFU.
+// Priority tiers (see SetWithPriority) apply first whatever the policy
+
+// Policy selects the eviction strategy of a cache, see WithPolicy
+type Policy int
+
+const (
+	// PolicyLRU evicts the least recently used entry, the default
+	PolicyLRU Policy = iota
+	// PolicyLFU evicts the least frequently used entry, the least recently used among equals
+	// unless WithLFUTieBreak says otherwise
+	PolicyLFU
+	// PolicyFIFO evicts the oldest insertion: Get doesn't reorder, an update counts as an insertion
+	PolicyFIFO
+)
+
+func (policy Policy) String() string {
+	switch policy {
+	case PolicyLRU:
+		return "LRU"
+	case PolicyLFU:
+		return "LFU"
+	case PolicyFIFO:
+		return "FIFO"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(policy))
+	}
+}
+
+// WithPolicy replaces LRU by another eviction policy
+func WithPolicy(policy Policy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// LFUTieBreak selects which of the least frequently used entries PolicyLFU evicts
+type LFUTieBreak int
+
+const (
+	// LFUTieLRU evicts the least recently used of them, the default
+	LFUTieLRU LFUTieBreak = iota
+	// LFUTieFIFO evicts the oldest insertion of them, in the order of InsertionOrder
+	LFUTieFIFO
+)
+
+// WithLFUTieBreak sets how PolicyLFU chooses between entries used equally often.
+// Other policies ignore it
+func WithLFUTieBreak(tieBreak LFUTieBreak) Option {
+	return func(o *options) {
+		o.lfuTieBreak = tieBreak
+	}
+}
+
+// NewCacheWithPolicy is NewCache evicting with policy, the same as passing WithPolicy(policy)
+func NewCacheWithPolicy[K comparable, V any](capacity int, policy Policy, opts ...Option) (*LruCache[K, V], error) {
+	return NewCache[K, V](capacity, append(opts, WithPolicy(policy))...)
+}
+
+// Policy returns the eviction policy of the cache
+func (cache *LruCache[K, V]) Policy() Policy {
+	return cache.policy
+}
+
+// leastFrequent returns the least frequently used entry of the priority tier, among equals the
+// nearest to the tail or the oldest insertion depending on the tie-break. The tier must have an entry
+func (cache *LruCache[K, V]) leastFrequent(priority int) *cacheNode[K, V] {
+	var victim *cacheNode[K, V]
+	node := cache.head.prev
+	for {
+		if node.priority == priority && (victim == nil || cache.lessFrequent(node, victim)) {
+			victim = node
+		}
+		if node == cache.head {
+			return victim
+		}
+		node = node.prev
+	}
+}
+
+// lessFrequent reports whether node should be evicted before victim, a node nearer to the tail.
+// Under LFUTieLRU the walk order already breaks ties: victim, seen first, wins them
+func (cache *LruCache[K, V]) lessFrequent(node, victim *cacheNode[K, V]) bool {
+	if node.frequency != victim.frequency {
+		return node.frequency < victim.frequency
+	}
+	return cache.lfuTieBreak == LFUTieFIFO && node.seq < victim.seq
+}
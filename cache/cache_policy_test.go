@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The same accesses leave a different victim under each policy:
+// a is the oldest insertion, b the least recently used, c the least frequently used
+func TestPolicyVictims(t *testing.T) {
+	tests := []struct {
+		policy Policy
+		victim string
+	}{
+		{PolicyLRU, "b"},
+		{PolicyLFU, "c"},
+		{PolicyFIFO, "a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			cache, err := NewCacheWithPolicy[string, string](3, test.policy)
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			cache.Set("a", "value")
+			cache.Set("b", "value")
+			cache.Get("b")
+			cache.Get("b")
+			cache.Set("c", "value")
+			cache.Get("a")
+
+			cache.Set("d", "value")
+			if cache.Contains(test.victim) {
+				t.Errorf("%s should have been evicted, keys are %v", test.victim, cache.Keys())
+			}
+			if cache.Len() != 3 {
+				t.Errorf("Len() = %d, want 3", cache.Len())
+			}
+			if err := verifyIntegrity(cache); err != nil {
+				t.Errorf("integrity check failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicyFIFOKeepsOrder(t *testing.T) {
+	cache, _ := NewCacheWithPolicy[string, string](3, PolicyFIFO)
+	cache.Set("a", "value")
+	cache.Set("b", "value")
+	cache.Set("c", "value")
+	cache.Get("a")
+	cache.Get("b")
+
+	want := []string{"c", "b", "a"}
+	if got := cache.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+
+	// an update is a new insertion
+	cache.Set("a", "updated")
+	cache.Set("d", "value")
+	want = []string{"d", "a", "c"}
+	if got := cache.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestPolicyLFUWithPriorities(t *testing.T) {
+	cache, _ := NewCacheWithPolicy[string, string](3, PolicyLFU)
+	cache.SetWithPriority("cold", "value", -1)
+	cache.Get("cold")
+	cache.Get("cold")
+	cache.Set("a", "value")
+	cache.Set("b", "value")
+
+	// the lowest tier goes first however often it is read
+	cache.Set("c", "value")
+	if cache.Contains("cold") {
+		t.Error("the low priority entry should have been evicted first")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestPolicyUnknown(t *testing.T) {
+	if _, err := NewCacheWithPolicy[string, string](3, Policy(42)); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+	if got := Policy(42).String(); got != "Policy(42)" {
+		t.Errorf("String() = %q, want Policy(42)", got)
+	}
+	cache, _ := NewStringCache(3)
+	if cache.Policy() != PolicyLRU {
+		t.Errorf("Policy() = %v, want LRU by default", cache.Policy())
+	}
+}
+
+func TestPolicyLFUTieBreak(t *testing.T) {
+	tests := []struct {
+		name     string
+		tieBreak LFUTieBreak
+		evicted  string
+	}{
+		{"LRU", LFUTieLRU, "b"},
+		{"FIFO", LFUTieFIFO, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := NewCacheWithPolicy[string, string](3, PolicyLFU, WithLFUTieBreak(tt.tieBreak))
+			// a, b and c are all read once: a is the oldest insertion, b the least recently used
+			cache.Set("a", "value")
+			cache.Set("b", "value")
+			cache.Get("b")
+			cache.Get("a")
+			cache.Set("c", "value")
+			cache.Get("c")
+
+			cache.Set("d", "value")
+			if cache.Contains(tt.evicted) {
+				t.Errorf("%s tie-break should have evicted %s, Keys() = %v", tt.name, tt.evicted, cache.Keys())
+			}
+			if cache.Len() != 3 {
+				t.Errorf("Len() = %d, want 3", cache.Len())
+			}
+		})
+	}
+
+	if _, err := NewCacheWithPolicy[string, string](3, PolicyLFU, WithLFUTieBreak(LFUTieBreak(42))); err == nil {
+		t.Error("expected an error for an unknown tie-break")
+	}
+}
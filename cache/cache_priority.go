@@ -0,0 +1,90 @@
+package cache
+
+// Entries belong to priority tiers, 0 unless set with SetWithPriority. Eviction takes the least
+// recently used entry of the lowest tier present: recency only orders entries of the same tier.
+// The tiers share the DLL, the victim is found walking from the tail. Entry counts per tier
+// tell which tier is the lowest, and keep eviction O(1) while every entry has the default priority
+
+// SetWithPriority is Set, placing the entry in the given priority tier.
+// Lower priority entries are evicted first whatever their recency, ties are broken by LRU.
+// A plain Set of an existing key keeps its priority. A value too large for the byte budget of a
+// NewSizeCache is dropped, as by Set
+func (cache *LruCache[K, V]) SetWithPriority(key K, value V, priority int) (updated bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	// an existing entry of key must not move to the tier of a refused value
+	if !cache.fits(key, value) {
+		return false
+	}
+	updated = cache.set(key, value)
+	node := cache.store[key]
+	cache.trackPriority(node.priority, -1)
+	node.priority = priority
+	cache.trackPriority(priority, 1)
+	return updated
+}
+
+// Priority returns the priority tier of key, ok is false if key is missing
+func (cache *LruCache[K, V]) Priority(key K) (priority int, ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	node, ok := cache.store[key]
+	if !ok || cache.expired(node) {
+		return 0, false
+	}
+	return node.priority, true
+}
+
+// evictionVictim returns the entry to evict from the lowest priority tier according to the
+// policy, the cache must not be empty
+func (cache *LruCache[K, V]) evictionVictim() *cacheNode[K, V] {
+	if len(cache.priorities) == 0 {
+		if cache.policy == PolicyLFU {
+			return cache.leastFrequent(0)
+		}
+		return cache.head.prev
+	}
+
+	// entries missing from the counts have the default priority
+	lowest, defaults := 0, len(cache.store)
+	first := true
+	for priority, count := range cache.priorities {
+		defaults -= count
+		if first || priority < lowest {
+			lowest, first = priority, false
+		}
+	}
+	if defaults > 0 {
+		lowest = min(lowest, 0)
+	}
+	if cache.policy == PolicyLFU {
+		return cache.leastFrequent(lowest)
+	}
+
+	// walk from the tail (LRU) toward the head
+	node := cache.head.prev
+	for node.priority != lowest {
+		node = node.prev
+	}
+	return node
+}
+
+// trackPriority adjusts the entry count of priority by delta, the default priority isn't counted
+func (cache *LruCache[K, V]) trackPriority(priority int, delta int) {
+	if priority == 0 {
+		return
+	}
+	if cache.priorities == nil {
+		cache.priorities = make(map[int]int)
+	}
+
+	cache.priorities[priority] += delta
+	if cache.priorities[priority] == 0 {
+		delete(cache.priorities, priority)
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetWithPriority(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.SetWithPriority("important", "value1", 10)
+	cache.SetWithPriority("disposable", "value2", -1)
+	cache.Set("regular", "value3")
+
+	// recency doesn't save the low priority entry
+	cache.Get("disposable")
+	cache.Set("new1", "value4")
+	if _, ok := cache.Get("disposable"); ok {
+		t.Error("the lowest priority entry should be evicted first")
+	}
+
+	// then the default tier goes, by LRU
+	cache.Set("new2", "value5")
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"new2", "new1", "important"}) {
+		t.Errorf("keys = %v, want [new2 new1 important]", got)
+	}
+
+	if priority, ok := cache.Priority("important"); !ok || priority != 10 {
+		t.Errorf("Priority(important) = (%v, %v), want (10, true)", priority, ok)
+	}
+	if _, ok := cache.Priority("disposable"); ok {
+		t.Error("Priority of a missing key should not be ok")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestPriorityKeptOnUpdate(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	cache.SetWithPriority("key1", "value1", 5)
+	cache.Set("key1", "updated")
+	cache.Get("key1")
+	if priority, _ := cache.Priority("key1"); priority != 5 {
+		t.Errorf("Priority(key1) after Set and Get = %d, want 5", priority)
+	}
+
+	cache.SetWithPriority("key1", "updated", 0)
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+	if _, ok := cache.Get("key1"); ok {
+		t.Error("key1 back to the default priority should be evicted by LRU")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
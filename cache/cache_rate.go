@@ -0,0 +1,85 @@
+package cache
+
+import "time"
+
+// The operation rate is computed over a trailing one second window split in fixed buckets.
+// Buckets live in a ring indexed by their absolute number (time / bucket width), a slot holding
+// an older bucket number is stale and gets reset when the clock reaches it again.
+// Counting an operation is O(1) and the whole thing uses a constant amount of memory
+
+const (
+	rateBuckets     = 10
+	rateBucketWidth = time.Second / rateBuckets
+)
+
+type opKind int
+
+const (
+	opGet opKind = iota
+	opSet
+	opDelete
+)
+
+type opsBucket struct {
+	id                  int64
+	gets, sets, deletes int
+}
+
+type opsRate struct {
+	buckets [rateBuckets]opsBucket
+}
+
+// record counts one operation in the bucket covering now
+func (rate *opsRate) record(now time.Time, op opKind) {
+	id := now.UnixNano() / int64(rateBucketWidth)
+	bucket := &rate.buckets[id%rateBuckets]
+	if bucket.id != id {
+		*bucket = opsBucket{id: id}
+	}
+
+	switch op {
+	case opGet:
+		bucket.gets++
+	case opSet:
+		bucket.sets++
+	case opDelete:
+		bucket.deletes++
+	}
+}
+
+// perSecond sums the buckets of the window ending at now
+func (rate *opsRate) perSecond(now time.Time) (gets, sets, deletes float64) {
+	id := now.UnixNano() / int64(rateBucketWidth)
+	for _, bucket := range rate.buckets {
+		if bucket.id <= id-rateBuckets || bucket.id > id {
+			continue
+		}
+		gets += float64(bucket.gets)
+		sets += float64(bucket.sets)
+		deletes += float64(bucket.deletes)
+	}
+
+	window := float64(rateBuckets*rateBucketWidth) / float64(time.Second)
+	return gets / window, sets / window, deletes / window
+}
+
+// countOp records an operation when rate tracking is enabled
+func (cache *LruCache[K, V]) countOp(op opKind) {
+	if cache.opsRate != nil {
+		cache.opsRate.record(cache.now(), op)
+	}
+}
+
+// OpsPerSecond returns the rate of Get, Set and Delete calls over the last second.
+// The window is made of 100ms buckets, the current (partial) bucket included.
+// It always returns zeros unless the cache was created WithOpsRateTracking
+func (cache *LruCache[K, V]) OpsPerSecond() (gets, sets, deletes float64) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.opsRate == nil {
+		return 0, 0, 0
+	}
+	return cache.opsRate.perSecond(cache.now())
+}
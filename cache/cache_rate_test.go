@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpsPerSecond(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now), WithOpsRateTracking())
+
+	// 3 gets, 2 sets and 1 delete in each of the 10 buckets of the window
+	for bucket := 0; bucket < rateBuckets; bucket++ {
+		cache.Set("key1", "value1")
+		cache.Set("key2", "value2")
+		cache.Get("key1")
+		cache.Get("key2")
+		cache.Get("missing")
+		cache.Delete("key2")
+		clock.Advance(rateBucketWidth)
+	}
+	// step back into the last bucket that received operations
+	clock.Advance(-time.Nanosecond)
+
+	steps := []struct {
+		name                string
+		advance             time.Duration
+		gets, sets, deletes float64
+	}{
+		{"full window", 0, 30, 20, 10},
+		{"half window elapsed", time.Second / 2, 15, 10, 5},
+		{"window elapsed", time.Second, 0, 0, 0},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			clock.Advance(step.advance)
+			gets, sets, deletes := cache.OpsPerSecond()
+			if gets != step.gets || sets != step.sets || deletes != step.deletes {
+				t.Errorf("OpsPerSecond() = (%v, %v, %v), want (%v, %v, %v)",
+					gets, sets, deletes, step.gets, step.sets, step.deletes)
+			}
+		})
+	}
+}
+
+func TestOpsPerSecondDisabled(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Get("key1")
+
+	if gets, sets, deletes := cache.OpsPerSecond(); gets != 0 || sets != 0 || deletes != 0 {
+		t.Errorf("OpsPerSecond() = (%v, %v, %v) without tracking, want zeros", gets, sets, deletes)
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readerPrealloc caps the buffer SetReader allocates upfront: size is only a claim until the bytes
+// are read, past the cap the buffer grows with the content actually read
+const readerPrealloc = 1 << 20
+
+// SetReader stores the next size bytes of r as the value of key.
+// The content is read before taking the lock, so a slow reader never blocks other operations,
+// and nothing is stored if r ends before size bytes were read.
+// A value too large for the byte budget of a NewSizeCache is not stored either, SetReader then
+// returns ErrEntryTooLarge.
+// SetReader and GetReader take the cache as argument, they need one holding string values
+func SetReader[K comparable](cache *LruCache[K, string], key K, r io.Reader, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("size must not be negative, got %d", size)
+	}
+
+	var builder strings.Builder
+	builder.Grow(int(min(size, readerPrealloc)))
+	if _, err := io.CopyN(&builder, r, size); err != nil {
+		return fmt.Errorf("reading value for key %v: %w", key, err)
+	}
+
+	if _, err := cache.TrySet(key, builder.String()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetReader is Get returning a reader over the stored bytes instead of the value itself.
+// The reader is backed by the cached value, no copy is made
+func GetReader[K comparable](cache *LruCache[K, string], key K) (io.ReadCloser, bool) {
+	value, ok := cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return io.NopCloser(strings.NewReader(value)), true
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestReaderRoundTrip(t *testing.T) {
+	cache, _ := NewStringCache(2)
+
+	// 4MB of random bytes, not valid utf-8
+	payload := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(payload)
+
+	// extra trailing bytes must be left unread
+	source := bytes.NewReader(append(payload, "trailing"...))
+	if err := SetReader(cache, "big", source, int64(len(payload))); err != nil {
+		t.Fatalf("SetReader failed: %v", err)
+	}
+	if source.Len() != len("trailing") {
+		t.Errorf("SetReader consumed %d extra bytes", len("trailing")-source.Len())
+	}
+
+	reader, ok := GetReader(cache, "big")
+	if !ok {
+		t.Fatal("GetReader(big) reported a miss")
+	}
+	defer reader.Close()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading value failed: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Error("value read back differs from the streamed one")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestSetReaderErrors(t *testing.T) {
+	cache, _ := NewStringCache(2)
+
+	tests := []struct {
+		name string
+		data string
+		size int64
+	}{
+		{"short reader", "abc", 10},
+		{"size far beyond the reader", "abc", 1 << 50},
+		{"negative size", "abc", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetReader(cache, "key", bytes.NewReader([]byte(tt.data)), tt.size); err == nil {
+				t.Errorf("SetReader(%q, %d) should fail", tt.data, tt.size)
+			}
+			if cache.Len() != 0 {
+				t.Errorf("nothing should be stored on error, Len() = %d", cache.Len())
+			}
+		})
+	}
+
+	if _, ok := GetReader(cache, "key"); ok {
+		t.Error("GetReader on a missing key should report a miss")
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A replica serves reads from an immutable snapshot of a primary cache. The snapshot is a plain
+// map published through an atomic pointer: readers never lock anything, a background goroutine
+// periodically copies the primary (under the primary's lock) into a new map and swaps it in.
+// Reads may be up to one refresh interval stale, and don't affect the LRU order of the primary
+
+// ReplicaCache is a read-only, eventually consistent view of an LruCache, see ReplicaView
+type ReplicaCache[K comparable, V any] struct {
+	primary  *LruCache[K, V]
+	snapshot atomic.Pointer[map[K]V]
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// ReplicaView returns a replica of the cache refreshed every refreshInterval.
+// The replica holds a goroutine until Stop is called.
+// It returns an error if refreshInterval is not positive, or for a cache created by
+// NewUnsafeCache: the refreshes would race with its owner
+func (cache *LruCache[K, V]) ReplicaView(refreshInterval time.Duration) (*ReplicaCache[K, V], error) {
+	if refreshInterval <= 0 {
+		return nil, fmt.Errorf("refresh interval must be greater than 0, got %v", refreshInterval)
+	}
+	if !cache.IsConcurrencySafe() {
+		return nil, fmt.Errorf("a replica requires a concurrency safe cache")
+	}
+	replica := &ReplicaCache[K, V]{
+		primary: cache,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	replica.refresh()
+	go replica.run(refreshInterval)
+	return replica, nil
+}
+
+func (replica *ReplicaCache[K, V]) run(refreshInterval time.Duration) {
+	defer close(replica.done)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			replica.refresh()
+		case <-replica.stop:
+			return
+		}
+	}
+}
+
+// refresh copies the live entries of the primary into a new snapshot
+func (replica *ReplicaCache[K, V]) refresh() {
+	cache := replica.primary
+
+	// protect DS
+	cache.mutex.Lock()
+	snapshot := make(map[K]V, len(cache.store))
+	for key, node := range cache.store {
+		if !cache.expired(node) {
+			snapshot[key] = node.value
+		}
+	}
+	cache.mutex.Unlock()
+
+	replica.snapshot.Store(&snapshot)
+}
+
+// Get returns the value of key as of the last refresh, without locking
+func (replica *ReplicaCache[K, V]) Get(key K) (value V, ok bool) {
+	value, ok = (*replica.snapshot.Load())[key]
+	return value, ok
+}
+
+// Len returns the number of entries as of the last refresh
+func (replica *ReplicaCache[K, V]) Len() int {
+	return len(*replica.snapshot.Load())
+}
+
+// Stop ends the refreshes, the replica keeps serving its last snapshot.
+// Calling Stop more than once is safe
+func (replica *ReplicaCache[K, V]) Stop() {
+	replica.stopOnce.Do(func() {
+		close(replica.stop)
+	})
+	<-replica.done
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or the timeout expires
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
+
+func TestReplicaView(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+
+	replica, _ := cache.ReplicaView(10 * time.Millisecond)
+	defer replica.Stop()
+
+	// the initial snapshot is taken right away
+	if val, ok := replica.Get("key1"); !ok || val != "value1" {
+		t.Errorf("replica Get(key1) = (%v, %v), want (value1, true)", val, ok)
+	}
+
+	cache.Set("key2", "value2")
+	cache.Delete("key1")
+	converged := waitFor(time.Second, func() bool {
+		_, hasKey1 := replica.Get("key1")
+		val, hasKey2 := replica.Get("key2")
+		return !hasKey1 && hasKey2 && val == "value2"
+	})
+	if !converged {
+		t.Error("replica did not reflect the primary within the timeout")
+	}
+	if replica.Len() != 1 {
+		t.Errorf("replica Len() = %d, want 1", replica.Len())
+	}
+
+	// replica reads don't promote entries of the primary
+	cache.Set("key3", "value3")
+	waitFor(time.Second, func() bool { return replica.Len() == 2 })
+	replica.Get("key2")
+	if cache.head.key != "key3" {
+		t.Errorf("primary head = %s after replica read, want key3", cache.head.key)
+	}
+}
+
+func TestReplicaReadsDontLockPrimary(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	replica, _ := cache.ReplicaView(time.Hour)
+	defer replica.Stop()
+
+	// hold the primary's lock while reading from the replica
+	cache.mutex.Lock()
+	read := make(chan bool)
+	go func() {
+		_, ok := replica.Get("key1")
+		read <- ok
+	}()
+
+	select {
+	case ok := <-read:
+		if !ok {
+			t.Error("replica Get(key1) missed")
+		}
+	case <-time.After(time.Second):
+		t.Error("replica read blocked on the primary's lock")
+	}
+	cache.mutex.Unlock()
+}
+
+func TestReplicaStop(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	replica, _ := cache.ReplicaView(time.Millisecond)
+	replica.Stop()
+	replica.Stop()
+
+	// a stopped replica keeps its last snapshot
+	cache.Set("key1", "value1")
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := replica.Get("key1"); ok {
+		t.Error("a stopped replica should not refresh")
+	}
+}
+
+func TestReplicaViewInvalid(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := cache.ReplicaView(interval); err == nil {
+			t.Errorf("ReplicaView(%v) should fail", interval)
+		}
+	}
+
+	unsafe, _ := NewUnsafeCache[string, string](5)
+	if replica, err := unsafe.ReplicaView(time.Millisecond); err == nil {
+		replica.Stop()
+		t.Error("ReplicaView on an unsafe cache should fail")
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+// Rotate starts a new generation: the contents of the cache move to a detached cache, which is
+// returned, and the cache continues empty with the same capacity and options.
+// The generation of the cache is incremented, the detached cache keeps the previous one.
+// Both happen in one locked step, no operation sees a partially rotated cache.
+// The detached cache is independent and keeps working, it only lacks the background resources
+// of the cache: it doesn't write to the audit log nor to the write-behind store, and pending
+// futures resolve into the rotated cache
+func (cache *LruCache[K, V]) Rotate() *LruCache[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	// the detached cache takes over the state as is, no copy needed
+	detached := *cache
+	detached.mutex = cache.newMutex()
+	detached.audit = nil
+	detached.writeBehind = nil
+	detached.futures = nil
+	detached.janitor = nil
+	detached.subscribers = nil
+
+	cache.reset()
+	cache.generation++
+	return &detached
+}
+
+// Generation returns the current generation of the cache, 0 until the first Rotate
+func (cache *LruCache[K, V]) Generation() uint64 {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.generation
+}
+
+// GetWithGeneration is Get, also returning the generation the value was set in.
+// Comparing it with Generation tells whether the cache was rotated since
+func (cache *LruCache[K, V]) GetWithGeneration(key K) (value V, generation uint64, ok bool) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	defer cache.debugCheck()
+
+	value, ok = cache.get(key)
+	if !ok {
+		return value, 0, false
+	}
+	return value, cache.store[key].generation, true
+}
+
+// reset replaces every piece of state of the cache by an empty one, keeping the configuration
+func (cache *LruCache[K, V]) reset() {
+	cache.clearEntries()
+	cache.seq = 0
+	cache.stats = cacheStats{}
+	if cache.opsRate != nil {
+		cache.opsRate = &opsRate{}
+	}
+	if cache.evictions != nil {
+		cache.evictions = &evictionHistory[K]{}
+	}
+	if cache.uniqueKeys != nil {
+		cache.uniqueKeys = newHyperLogLog()
+	}
+	if cache.trace != nil {
+		cache.trace = &evictionTrace[K]{}
+	}
+}
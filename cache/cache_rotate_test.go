@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	cache, _ := NewStringCache(3, WithSecondaryIndex(emailOf))
+	cache.Set("user1", "ann|ann@example.com")
+	cache.Set("user2", "bob|bob@example.com")
+	cache.SetWithDeps("page", "html|", []string{"user1"})
+
+	old := cache.Rotate()
+
+	// the detached cache keeps the contents
+	if got := listKeys(old); !reflect.DeepEqual(got, []string{"page", "user2", "user1"}) {
+		t.Errorf("detached cache keys = %v, want [page user2 user1]", got)
+	}
+	if key, _, ok := old.GetByIndex("bob@example.com"); !ok || key != "user2" {
+		t.Errorf("detached GetByIndex(bob) = (%v, %v), want (user2, true)", key, ok)
+	}
+	if err := verifyIntegrity(old); err != nil {
+		t.Errorf("detached integrity check failed: %v", err)
+	}
+
+	// the rotated cache starts empty with the same configuration
+	if cache.Len() != 0 || cache.Capacity() != 3 {
+		t.Errorf("rotated cache Len() = %d, Capacity() = %d, want 0 and 3", cache.Len(), cache.Capacity())
+	}
+	if _, _, ok := cache.GetByIndex("bob@example.com"); ok {
+		t.Error("rotated cache should have an empty index")
+	}
+	cache.Set("user3", "cid|cid@example.com")
+	if key, _, ok := cache.GetByIndex("cid@example.com"); !ok || key != "user3" {
+		t.Errorf("rotated GetByIndex(cid) = (%v, %v), want (user3, true)", key, ok)
+	}
+
+	// the two caches are independent
+	old.Delete("user1")
+	if old.Len() != 1 || cache.Len() != 1 {
+		t.Errorf("Len() = %d and %d after deleting from the detached cache, want 1 and 1", old.Len(), cache.Len())
+	}
+	for _, c := range []*StringCache{cache, old} {
+		if err := verifyIntegrity(c); err != nil {
+			t.Errorf("integrity check failed: %v", err)
+		}
+	}
+}
+
+func TestGenerations(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.Set("old", "value1")
+	if gen := cache.Generation(); gen != 0 {
+		t.Errorf("Generation() before any Rotate = %d, want 0", gen)
+	}
+
+	detached := cache.Rotate()
+	cache.Set("new", "value2")
+	if gen := cache.Generation(); gen != 1 {
+		t.Errorf("Generation() after Rotate = %d, want 1", gen)
+	}
+
+	// the value read from the detached cache predates the rotation
+	_, oldGen, ok := detached.GetWithGeneration("old")
+	if !ok || oldGen == cache.Generation() {
+		t.Errorf("detached GetWithGeneration(old) = (%v, %v), want an older generation than %d", oldGen, ok, cache.Generation())
+	}
+	value, newGen, ok := cache.GetWithGeneration("new")
+	if !ok || value != "value2" || newGen != cache.Generation() {
+		t.Errorf("GetWithGeneration(new) = (%v, %v, %v), want (value2, %d, true)", value, newGen, ok, cache.Generation())
+	}
+	// promotion keeps the generation
+	cache.Set("other", "value3")
+	if _, gen, _ := cache.GetWithGeneration("new"); gen != newGen {
+		t.Errorf("generation after promotion = %d, want %d", gen, newGen)
+	}
+	if _, _, ok := cache.GetWithGeneration("old"); ok {
+		t.Error("the rotated cache should not hold old")
+	}
+}
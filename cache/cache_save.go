@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// A saved cache is a gob stream of a savedCache: the capacity, then the entries from MRU to LRU.
+// Only keys and values are saved, the entries are loaded back as plain Sets: TTLs, priorities and
+// dependencies are not restored. Keys and values must be encodable by encoding/gob
+
+type savedCache[K comparable, V any] struct {
+	Capacity int
+	Entries  []Entry[K, V]
+}
+
+// Save writes the capacity and the entries of the cache, from MRU to LRU, for Load or
+// NewCacheFromReader. The entries are copied under the lock and encoded after releasing it
+func (cache *LruCache[K, V]) Save(w io.Writer) error {
+	// protect DS
+	cache.mutex.Lock()
+	saved := savedCache[K, V]{Capacity: cache.capacity, Entries: cache.entries()}
+	cache.mutex.Unlock()
+
+	if err := gob.NewEncoder(w).Encode(saved); err != nil {
+		return fmt.Errorf("saving cache: %w", err)
+	}
+	return nil
+}
+
+// LoadOptions tune how LoadWith restores the saved entries
+type LoadOptions struct {
+	// PreserveOrder restores the saved recency order, the saved MRU ending up the head. Otherwise
+	// the saved order is dropped: entries are set as they are read, the saved MRU first, so the
+	// entries that were the most recently used are the first to be evicted afterward
+	PreserveOrder bool
+}
+
+// Load replaces the contents of the cache by the entries written by Save, keeping their recency
+// order. The cache keeps its own capacity: if the saved entries don't fit, only the most recently
+// used ones are loaded. On a corrupt or truncated input an error is returned and the cache is left
+// untouched
+func (cache *LruCache[K, V]) Load(r io.Reader) error {
+	return cache.LoadWith(r, LoadOptions{PreserveOrder: true})
+}
+
+// LoadWith is Load, restoring the saved order or not according to opts
+func (cache *LruCache[K, V]) LoadWith(r io.Reader, opts LoadOptions) error {
+	saved, err := readSaved[K, V](r)
+	if err != nil {
+		return err
+	}
+
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	cache.clearEntries()
+	cache.loadEntries(saved.Entries, opts.PreserveOrder)
+	return nil
+}
+
+// NewCacheFromReader creates a cache with the capacity and the entries written by Save
+func NewCacheFromReader[K comparable, V any](r io.Reader, opts ...Option) (*LruCache[K, V], error) {
+	saved, err := readSaved[K, V](r)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := NewCache[K, V](saved.Capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cache.loadEntries(saved.Entries, true)
+	return cache, nil
+}
+
+func readSaved[K comparable, V any](r io.Reader) (saved savedCache[K, V], err error) {
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return saved, fmt.Errorf("loading cache: %w", err)
+	}
+	return saved, nil
+}
+
+// loadEntries sets entries, given from MRU to LRU, so that the first one ends up the head when
+// preserveOrder is set, the last one otherwise.
+// Entries past the capacity would be evicted right away, they are skipped
+func (cache *LruCache[K, V]) loadEntries(entries []Entry[K, V], preserveOrder bool) {
+	entries = entries[:min(len(entries), cache.capacity)]
+	if !preserveOrder {
+		for _, entry := range entries {
+			cache.set(entry.Key, entry.Value)
+		}
+		return
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		cache.set(entries[i].Key, entries[i].Value)
+	}
+}
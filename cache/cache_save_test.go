@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	for i := 1; i <= 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	cache.Get("key2")
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved := buf.Bytes()
+
+	restored, err := NewCacheFromReader[string, string](bytes.NewReader(saved))
+	if err != nil {
+		t.Fatalf("NewCacheFromReader failed: %v", err)
+	}
+	if restored.Capacity() != 5 {
+		t.Errorf("Capacity() = %d, want 5", restored.Capacity())
+	}
+	if got, want := restored.Entries(), cache.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored entries = %v, want %v", got, want)
+	}
+	if err := verifyIntegrity(restored); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	// Load replaces the contents and keeps the capacity of the receiver
+	smaller, _ := NewStringCache(3)
+	smaller.Set("other", "value")
+	if err := smaller.Load(bytes.NewReader(saved)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := []string{"key2", "key5", "key4"}
+	if got := smaller.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() after Load = %v, want %v", got, want)
+	}
+	if err := verifyIntegrity(smaller); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestLoadCorruptInput(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	var buf bytes.Buffer
+	cache.Save(&buf)
+	saved := buf.Bytes()
+
+	inputs := map[string][]byte{
+		"empty":     nil,
+		"truncated": saved[:len(saved)/2],
+		"garbage":   []byte("not a saved cache"),
+	}
+	for name, input := range inputs {
+		target, _ := NewStringCache(5)
+		target.Set("existing", "value")
+		if err := target.Load(bytes.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if got := target.Keys(); !reflect.DeepEqual(got, []string{"existing"}) {
+			t.Errorf("%s: a failed Load changed the cache to %v", name, got)
+		}
+		if _, err := NewCacheFromReader[string, string](bytes.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error from NewCacheFromReader", name)
+		}
+	}
+}
+
+func TestLoadWithOrder(t *testing.T) {
+	cache, _ := NewStringCache(4)
+	for i := 1; i <= 4; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	cache.Get("key2")
+	// order from MRU to LRU: key2, key4, key3, key1
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved := buf.Bytes()
+
+	tests := []struct {
+		name string
+		opts LoadOptions
+		want []string
+	}{
+		{"preserved", LoadOptions{PreserveOrder: true}, []string{"key2", "key4", "key3", "key1"}},
+		{"reset", LoadOptions{}, []string{"key1", "key3", "key4", "key2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, _ := NewStringCache(4)
+			if err := loaded.LoadWith(bytes.NewReader(saved), tt.opts); err != nil {
+				t.Fatalf("LoadWith failed: %v", err)
+			}
+			if got := loaded.Keys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Keys() after LoadWith(%+v) = %v, want %v", tt.opts, got, tt.want)
+			}
+			// the first eviction takes the entry loaded first
+			loaded.Set("new", "value")
+			if loaded.Contains(tt.want[len(tt.want)-1]) {
+				t.Errorf("Set after LoadWith(%+v) should have evicted %s", tt.opts, tt.want[len(tt.want)-1])
+			}
+			if err := verifyIntegrity(loaded); err != nil {
+				t.Errorf("integrity check failed: %v", err)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package cache
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Unit tests to test the functioning of the cache in a sequential manner
@@ -18,20 +22,20 @@ func TestNewCache(t *testing.T) {
 
 	for _, tt := range testsTable {
 		t.Run(tt.name, func(t *testing.T) {
-			cache, err := NewCache(tt.capacity)
+			cache, err := NewStringCache(tt.capacity)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("NewCache(%d) error = %v, wantErr %v", tt.capacity, err, tt.wantErr)
+				t.Errorf("NewStringCache(%d) error = %v, wantErr %v", tt.capacity, err, tt.wantErr)
 				return
 			}
 			if !tt.wantErr && cache == nil {
-				t.Errorf("NewCache(%d) returned nil cache without error", tt.capacity)
+				t.Errorf("NewStringCache(%d) returned nil cache without error", tt.capacity)
 			}
 		})
 	}
 }
 
 func TestBasicOperations(t *testing.T) {
-	cache, err := NewCache(3)
+	cache, err := NewStringCache(3)
 	if err != nil {
 		t.Fatalf("failed to create cache: %v", err)
 	}
@@ -89,7 +93,7 @@ func TestBasicOperations(t *testing.T) {
 // Caches with size 1 will test the limit of the Circular-DL implementation because it will
 // trigger node movements in the DLL extensively, this can be seen as a stress test
 func TestSingleElement(t *testing.T) {
-	cache, _ := NewCache(1)
+	cache, _ := NewStringCache(1)
 
 	t.Run("single element operations", func(t *testing.T) {
 		// Add element
@@ -149,7 +153,7 @@ func TestSingleElement(t *testing.T) {
 }
 
 func TestEviction(t *testing.T) {
-	cache, _ := NewCache(2)
+	cache, _ := NewStringCache(2)
 
 	// Test eviction sequence
 	steps := []struct {
@@ -192,7 +196,7 @@ func TestEviction(t *testing.T) {
 }
 
 func TestEmptyCache(t *testing.T) {
-	cache, _ := NewCache(1)
+	cache, _ := NewStringCache(1)
 
 	t.Run("empty cache operations", func(t *testing.T) {
 		// Test Get
@@ -212,3 +216,878 @@ func TestEmptyCache(t *testing.T) {
 		}
 	})
 }
+
+// Getting the current head should be a no-op for the DLL: same head node, same store entry
+func TestGetHeadFastPath(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	head := cache.head
+	if head.key != "key2" {
+		t.Fatalf("expected key2 at head, got %s", head.key)
+	}
+
+	for i := 0; i < 3; i++ {
+		if val, ok := cache.Get("key2"); !ok || val != "value2" {
+			t.Errorf("Get(key2) = (%v, %v), want (value2, true)", val, ok)
+		}
+		if cache.head != head {
+			t.Error("head node changed after getting the MRU key")
+		}
+		if cache.store["key2"] != head {
+			t.Error("store points to a different node after getting the MRU key")
+		}
+		if err := verifyIntegrity(cache); err != nil {
+			t.Errorf("integrity check failed after Get on head: %v", err)
+		}
+	}
+
+	// getting a non-head key still promotes it
+	cache.Get("key1")
+	if cache.head.key != "key1" {
+		t.Errorf("expected key1 at head after Get, got %s", cache.head.key)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after promotion: %v", err)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	tests := []struct {
+		name     string
+		key      string
+		expected string
+		want     bool
+		wantLen  int
+	}{
+		{"mismatch keeps entry", "key1", "other", false, 2},
+		{"missing key", "nonexistent", "value1", false, 2},
+		{"match deletes entry", "key1", "value1", true, 1},
+		{"already deleted", "key1", "value1", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareAndDelete(cache, tt.key, tt.expected); got != tt.want {
+				t.Errorf("CompareAndDelete(%s, %s) = %v, want %v", tt.key, tt.expected, got, tt.want)
+			}
+			if cache.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", cache.Len(), tt.wantLen)
+			}
+			if err := verifyIntegrity(cache); err != nil {
+				t.Errorf("integrity check failed after CompareAndDelete: %v", err)
+			}
+		})
+	}
+
+	if val, ok := cache.Get("key2"); !ok || val != "value2" {
+		t.Errorf("Get(key2) = (%v, %v), want (value2, true)", val, ok)
+	}
+}
+
+// The store can't outgrow the capacity through the public API, simulate a bug by linking
+// nodes by hand and check that a single Set restores the invariant
+func TestSetRestoresCapacity(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	for _, key := range []string{"key1", "key2", "key3", "key4", "key5", "key6"} {
+		cache.store[key] = cache.addToHead(key, "value")
+		cache.currentBytes += entrySize(key, "value")
+	}
+	if err := verifyIntegrity(cache); err == nil {
+		t.Fatal("integrity check should fail on an over-filled cache")
+	}
+
+	cache.Set("key7", "value")
+	if cache.Len() != 3 {
+		t.Errorf("Len() = %d after Set, want 3", cache.Len())
+	}
+	for _, key := range []string{"key5", "key6", "key7"} {
+		if _, ok := cache.store[key]; !ok {
+			t.Errorf("%s should have survived the eviction", key)
+		}
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after Set: %v", err)
+	}
+}
+
+func TestOnMissHook(t *testing.T) {
+	var missed []string
+	var cache *StringCache
+	cache, _ = NewStringCache(2, WithOnMiss(func(key string) {
+		missed = append(missed, key)
+		// the hook runs unlocked, using the cache must not deadlock
+		cache.Len()
+	}))
+
+	cache.Set("key1", "value1")
+	cache.Get("key1")
+	cache.Get("missing1")
+	cache.Get("key1")
+	cache.Get("missing2")
+
+	if len(missed) != 2 || missed[0] != "missing1" || missed[1] != "missing2" {
+		t.Errorf("OnMiss called with %v, want [missing1 missing2]", missed)
+	}
+
+	// the hook doesn't populate the cache
+	if _, ok := cache.Get("missing1"); ok {
+		t.Error("OnMiss should not change the result of Get")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+}
+
+func TestGetRandom(t *testing.T) {
+	cache, _ := NewStringCache(10)
+	if _, _, ok := cache.GetRandom(); ok {
+		t.Error("GetRandom on empty cache should return false")
+	}
+
+	live := map[string]string{"key1": "value1", "key2": "value2", "key3": "value3", "key4": "value4", "key5": "value5"}
+	for key, value := range live {
+		cache.Set(key, value)
+	}
+	cache.Set("evicted", "value")
+	cache.Delete("evicted")
+	head := cache.head
+
+	const draws = 2000
+	seen := make(map[string]int)
+	for i := 0; i < draws; i++ {
+		key, value, ok := cache.GetRandom()
+		if !ok {
+			t.Fatal("GetRandom on a populated cache should return true")
+		}
+		if live[key] != value {
+			t.Fatalf("GetRandom() = (%s, %s), not a live entry", key, value)
+		}
+		seen[key]++
+	}
+
+	// every entry should come up a fair number of times
+	for key := range live {
+		if seen[key] < draws/len(live)/4 {
+			t.Errorf("%s drawn %d times out of %d", key, seen[key], draws)
+		}
+	}
+	if cache.head != head {
+		t.Error("GetRandom should not promote entries")
+	}
+}
+
+func TestSuspendEviction(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.SuspendEviction()
+
+	// load twice the capacity
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	if cache.Len() != 10 {
+		t.Errorf("Len() = %d while suspended, want 10", cache.Len())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed while suspended: %v", err)
+	}
+
+	// promote an old entry so it survives
+	cache.Get("key0")
+
+	if evicted := cache.ResumeEviction(); evicted != 5 {
+		t.Errorf("ResumeEviction() = %d, want 5", evicted)
+	}
+	if cache.Len() != 5 {
+		t.Errorf("Len() = %d after resume, want 5", cache.Len())
+	}
+	for i := 0; i < 10; i++ {
+		_, ok := cache.Get(fmt.Sprintf("key%d", i))
+		if want := i == 0 || i >= 6; ok != want {
+			t.Errorf("Get(key%d) ok = %v, want %v", i, ok, want)
+		}
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed after resume: %v", err)
+	}
+
+	// eviction is back to normal
+	cache.Set("key10", "value")
+	if cache.Len() != 5 {
+		t.Errorf("Len() = %d after resume, want 5", cache.Len())
+	}
+	if evicted := cache.ResumeEviction(); evicted != 0 {
+		t.Errorf("ResumeEviction() without suspension = %d, want 0", evicted)
+	}
+}
+
+func TestUnsafeCache(t *testing.T) {
+	unsafe, err := NewUnsafeCache[string, string](2)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if unsafe.IsConcurrencySafe() {
+		t.Error("NewUnsafeCache should not be concurrency safe")
+	}
+	if _, err := NewUnsafeCache[string, string](0); err == nil {
+		t.Error("NewUnsafeCache[string, string](0) should fail")
+	}
+
+	safe, _ := NewStringCache(2)
+	if !safe.IsConcurrencySafe() {
+		t.Error("NewCache should be concurrency safe")
+	}
+	if !safe.Clone().IsConcurrencySafe() || unsafe.Clone().IsConcurrencySafe() {
+		t.Error("Clone should keep the locking mode")
+	}
+
+	// same core, same behaviour
+	unsafe.Set("key1", "value1")
+	unsafe.Set("key2", "value2")
+	unsafe.Get("key1")
+	unsafe.Set("key3", "value3")
+	if _, ok := unsafe.Get("key2"); ok {
+		t.Error("key2 should have been evicted")
+	}
+	if val, ok := unsafe.Get("key1"); !ok || val != "value1" {
+		t.Errorf("Get(key1) = (%v, %v), want (value1, true)", val, ok)
+	}
+	if err := verifyIntegrity(unsafe); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "banana")
+	cache.Set("key2", "cherry")
+	cache.Set("key3", "apple")
+	cache.Set("key4", "banana")
+
+	tests := []struct {
+		name string
+		less func(a, b Entry[string, string]) bool
+		want []string
+	}{
+		{
+			"by value, ties keep recency",
+			func(a, b Entry[string, string]) bool { return a.Value < b.Value },
+			[]string{"key3", "key4", "key1", "key2"},
+		},
+		{
+			"by key priority",
+			func(a, b Entry[string, string]) bool {
+				priority := map[string]int{"key2": 0, "key4": 1, "key1": 2, "key3": 3}
+				return priority[a.Key] < priority[b.Key]
+			},
+			[]string{"key2", "key4", "key1", "key3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache.Reorder(tt.less)
+			if got := listKeys(cache); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order after Reorder = %v, want %v", got, tt.want)
+			}
+			if err := verifyIntegrity(cache); err != nil {
+				t.Errorf("integrity check failed after Reorder: %v", err)
+			}
+		})
+	}
+
+	// the new order drives eviction
+	cache.Set("key5", "date")
+	cache.Set("key6", "elderberry")
+	if _, ok := cache.Get("key3"); ok {
+		t.Error("key3 was reordered last and should have been evicted")
+	}
+
+	empty, _ := NewStringCache(2)
+	empty.Reorder(func(a, b Entry[string, string]) bool { return a.Key < b.Key })
+	if err := verifyIntegrity(empty); err != nil {
+		t.Errorf("integrity check failed after Reorder on empty cache: %v", err)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+	cache.Get("key1")
+	// order from MRU to LRU: key1, key3, key2
+
+	tests := []struct {
+		name     string
+		key      string
+		wantPrev *Entry[string, string]
+		wantNext *Entry[string, string]
+		wantOk   bool
+	}{
+		{"head", "key1", nil, &Entry[string, string]{"key3", "value3"}, true},
+		{"middle", "key3", &Entry[string, string]{"key1", "value1"}, &Entry[string, string]{"key2", "value2"}, true},
+		{"tail", "key2", &Entry[string, string]{"key3", "value3"}, nil, true},
+		{"missing", "nonexistent", nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, next, ok := cache.Neighbors(tt.key)
+			if ok != tt.wantOk || !reflect.DeepEqual(prev, tt.wantPrev) || !reflect.DeepEqual(next, tt.wantNext) {
+				t.Errorf("Neighbors(%s) = (%v, %v, %v), want (%v, %v, %v)",
+					tt.key, prev, next, ok, tt.wantPrev, tt.wantNext, tt.wantOk)
+			}
+		})
+	}
+
+	// querying doesn't promote
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"key1", "key3", "key2"}) {
+		t.Errorf("order after Neighbors = %v, want [key1 key3 key2]", got)
+	}
+
+	// a single entry has no neighbors
+	single, _ := NewStringCache(1)
+	single.Set("key1", "value1")
+	if prev, next, ok := single.Neighbors("key1"); !ok || prev != nil || next != nil {
+		t.Errorf("Neighbors on a single entry = (%v, %v, %v), want (nil, nil, true)", prev, next, ok)
+	}
+}
+
+func TestNeighborsSkipExpired(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewStringCache(5, WithClock(clock.Now))
+	cache.SetWithTTL("key1", "value1", time.Second)
+	cache.Set("key2", "value2")
+	cache.SetWithTTL("key3", "value3", time.Second)
+	cache.Set("key4", "value4")
+	cache.SetWithTTL("key5", "value5", time.Second)
+	// order from MRU to LRU: key5, key4, key3, key2, key1
+	clock.Advance(2 * time.Second)
+
+	prev, next, ok := cache.Neighbors("key2")
+	if !ok || !reflect.DeepEqual(prev, &Entry[string, string]{"key4", "value4"}) || next != nil {
+		t.Errorf("Neighbors(key2) = (%v, %v, %v), want (key4, nil, true)", prev, next, ok)
+	}
+	prev, next, ok = cache.Neighbors("key4")
+	if !ok || prev != nil || !reflect.DeepEqual(next, &Entry[string, string]{"key2", "value2"}) {
+		t.Errorf("Neighbors(key4) = (%v, %v, %v), want (nil, key2, true)", prev, next, ok)
+	}
+}
+
+func TestKeyLengthStats(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	if min, max, mean := KeyLengthStats(cache); min != 0 || max != 0 || mean != 0 {
+		t.Errorf("KeyLengthStats() on empty cache = (%d, %d, %v), want (0, 0, 0)", min, max, mean)
+	}
+
+	for _, key := range []string{"a", "abcd", "ab", "abcdefgh", ""} {
+		cache.Set(key, "value")
+	}
+	if min, max, mean := KeyLengthStats(cache); min != 0 || max != 8 || mean != 3 {
+		t.Errorf("KeyLengthStats() = (%d, %d, %v), want (0, 8, 3)", min, max, mean)
+	}
+
+	cache.Delete("")
+	cache.Delete("abcdefgh")
+	if min, max, mean := KeyLengthStats(cache); min != 1 || max != 4 || mean != 7.0/3 {
+		t.Errorf("KeyLengthStats() after deletes = (%d, %d, %v), want (1, 4, %v)", min, max, mean, 7.0/3)
+	}
+}
+
+func TestEstimatedHeapBytes(t *testing.T) {
+	cache, _ := NewStringCache(2000)
+	empty := cache.EstimatedHeapBytes()
+	if empty <= 0 {
+		t.Fatalf("EstimatedHeapBytes() on empty cache = %d, want > 0", empty)
+	}
+
+	raw := 0
+	for i := 0; i < 1000; i++ {
+		key, value := fmt.Sprintf("key%04d", i), fmt.Sprintf("value%04d", i)
+		cache.Set(key, value)
+		raw += len(key) + len(value)
+	}
+	thousand := cache.EstimatedHeapBytes()
+	for i := 1000; i < 2000; i++ {
+		cache.Set(fmt.Sprintf("key%04d", i), fmt.Sprintf("value%04d", i))
+	}
+	twoThousand := cache.EstimatedHeapBytes()
+
+	// every entry has the same size, the estimate grows linearly
+	if perThousand := thousand - empty; twoThousand-thousand != perThousand {
+		t.Errorf("estimate grew by %d then %d for the same number of entries", perThousand, twoThousand-thousand)
+	}
+	// nodes and map entries weigh more than the short keys and values themselves
+	if overhead := float64(thousand-empty) / float64(raw); overhead < 2 || overhead > 20 {
+		t.Errorf("estimate is %.1f times the raw key and value bytes, want between 2 and 20", overhead)
+	}
+}
+
+func TestPromotionBatching(t *testing.T) {
+	cache, _ := NewStringCache(3, WithPromotionBatching(2))
+	cache.Set("hot", "value")
+	cache.Set("key1", "value")
+	cache.Set("key2", "value")
+
+	// a single Get doesn't promote yet, the second one does
+	cache.Get("hot")
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"key2", "key1", "hot"}) {
+		t.Errorf("keys after one Get = %v, want [key2 key1 hot]", got)
+	}
+	cache.Get("hot")
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"hot", "key2", "key1"}) {
+		t.Errorf("keys after two Gets = %v, want [hot key2 key1]", got)
+	}
+
+	// a key read often enough keeps being promoted and outlives a stream of insertions
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("scan%d", i), "value")
+		cache.Get("hot")
+		cache.Get("hot")
+	}
+	if _, ok := cache.Get("hot"); !ok {
+		t.Error("frequently read key was evicted")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	if _, err := NewStringCache(3, WithPromotionBatching(0)); err == nil {
+		t.Error("NewCache should reject a promotion batch of 0")
+	}
+}
+
+func TestFreeSlots(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	for i, want := range []int{2, 1, 0, 0} {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+		if got := cache.FreeSlots(); got != want {
+			t.Errorf("FreeSlots() after %d Sets = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// never negative, even past the capacity
+	cache.SuspendEviction()
+	cache.Set("extra", "value")
+	if got := cache.FreeSlots(); got != 0 {
+		t.Errorf("FreeSlots() past capacity = %d, want 0", got)
+	}
+	cache.ResumeEviction()
+
+	cache.Delete("extra")
+	if got := cache.FreeSlots(); got != 1 {
+		t.Errorf("FreeSlots() after Delete = %d, want 1", got)
+	}
+}
+
+func TestPromoteAll(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	for i := 1; i <= 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	promoted := cache.PromoteAll([]string{"key1", "missing", "key3", "key5", "key2"})
+	if promoted != 4 {
+		t.Errorf("PromoteAll() = %d, want 4", promoted)
+	}
+	want := []string{"key2", "key5", "key3", "key1", "key4"}
+	if got := listKeys(cache); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys after PromoteAll = %v, want %v", got, want)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	if promoted := cache.PromoteAll(nil); promoted != 0 {
+		t.Errorf("PromoteAll(nil) = %d, want 0", promoted)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	if value, ok := cache.Peek("key1"); !ok || value != "value1" {
+		t.Errorf("Peek(key1) = (%v, %v), want (value1, true)", value, ok)
+	}
+	if _, ok := cache.Peek("missing"); ok {
+		t.Error("Peek(missing) should not be ok")
+	}
+
+	// peeking at the tail doesn't rescue it from the next eviction
+	cache.Set("key3", "value3")
+	if _, ok := cache.Peek("key1"); ok {
+		t.Error("key1 should have been evicted despite being peeked at")
+	}
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"key3", "key2"}) {
+		t.Errorf("keys = %v, want [key3 key2]", got)
+	}
+}
+
+type point struct{ x, y int }
+
+func TestGenericCache(t *testing.T) {
+	cache, err := NewCache[int, point](2)
+	if err != nil {
+		t.Fatalf("NewCache[int, point] error = %v", err)
+	}
+	cache.Set(1, point{1, 1})
+	cache.Set(2, point{2, 2})
+	cache.Get(1)
+	cache.Set(3, point{3, 3})
+
+	if value, ok := cache.Get(1); !ok || value != (point{1, 1}) {
+		t.Errorf("Get(1) = (%v, %v), want ({1 1}, true)", value, ok)
+	}
+	// a miss returns the zero value of the value type
+	if value, ok := cache.Get(2); ok || value != (point{}) {
+		t.Errorf("Get(2) = (%v, %v), want ({0 0}, false)", value, ok)
+	}
+	if !CompareAndDelete(cache, 3, point{3, 3}) {
+		t.Error("CompareAndDelete(3) with the current value should delete")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestOptionTypeMismatch(t *testing.T) {
+	// options are built without knowing the types of the cache, a mismatch fails the construction
+	if _, err := NewCache[int, string](2, WithOnMiss(func(key string) {})); err == nil {
+		t.Error("NewCache[int, string] should reject a WithOnMiss hook taking string keys")
+	}
+	if _, err := NewCache[string, int](2, WithStringInterning()); err == nil {
+		t.Error("NewCache[string, int] should reject string interning")
+	}
+	if _, err := NewCache[int, string](2, WithOnMiss(func(key int) {})); err != nil {
+		t.Errorf("NewCache[int, string] with a matching hook error = %v", err)
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	for i := 1; i <= 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	cache.Get("key2")
+
+	if _, err := cache.Resize(0); err == nil {
+		t.Error("expected an error for a capacity of 0")
+	}
+	if cache.Capacity() != 5 {
+		t.Errorf("Capacity() after a rejected Resize = %d, want 5", cache.Capacity())
+	}
+
+	evicted, err := cache.Resize(2)
+	if err != nil || evicted != 3 {
+		t.Errorf("Resize(2) = (%d, %v), want (3, nil)", evicted, err)
+	}
+	want := []string{"key2", "key5"}
+	if got := listKeys(cache); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys after Resize(2) = %v, want %v", got, want)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	// growing evicts nothing and leaves room for new entries
+	if evicted, _ := cache.Resize(4); evicted != 0 {
+		t.Errorf("Resize(4) evicted %d entries, want 0", evicted)
+	}
+	cache.Set("key6", "value")
+	cache.Set("key7", "value")
+	if cache.Len() != 4 || cache.Capacity() != 4 {
+		t.Errorf("Len() = %d, Capacity() = %d, want 4 and 4", cache.Len(), cache.Capacity())
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestClear(t *testing.T) {
+	evictions := 0
+	cache, _ := NewStringCache(3, WithOnEvict(func(key, value string) { evictions++ }))
+	for i := 1; i <= 3; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("Len() after Clear = %d, want 0", cache.Len())
+	}
+	if _, ok := cache.Get("key1"); ok {
+		t.Error("key1 should be gone after Clear")
+	}
+	if evictions != 0 {
+		t.Errorf("Clear called the eviction hook %d times, want 0", evictions)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed on the emptied cache: %v", err)
+	}
+
+	// the cache works as new afterward
+	for i := 4; i <= 7; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	want := []string{"key7", "key6", "key5"}
+	if got := listKeys(cache); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys after repopulating = %v, want %v", got, want)
+	}
+	if evictions != 1 {
+		t.Errorf("eviction hook called %d times, want 1", evictions)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestKeysAndEntries(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	if keys := cache.Keys(); keys == nil || len(keys) != 0 {
+		t.Errorf("Keys() on an empty cache = %#v, want an empty slice", keys)
+	}
+	if entries := cache.Entries(); entries == nil || len(entries) != 0 {
+		t.Errorf("Entries() on an empty cache = %#v, want an empty slice", entries)
+	}
+
+	for i := 1; i <= 4; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	cache.Get("key2")
+
+	wantKeys := []string{"key2", "key4", "key3", "key1"}
+	if got := cache.Keys(); !reflect.DeepEqual(got, wantKeys) {
+		t.Errorf("Keys() = %v, want %v", got, wantKeys)
+	}
+	wantEntries := []Entry[string, string]{
+		{Key: "key2", Value: "value2"},
+		{Key: "key4", Value: "value4"},
+		{Key: "key3", Value: "value3"},
+		{Key: "key1", Value: "value1"},
+	}
+	if got := cache.Entries(); !reflect.DeepEqual(got, wantEntries) {
+		t.Errorf("Entries() = %v, want %v", got, wantEntries)
+	}
+
+	// a single entry links to itself, the walk must still stop
+	single, _ := NewStringCache(1)
+	single.Set("only", "value")
+	if got := single.Keys(); !reflect.DeepEqual(got, []string{"only"}) {
+		t.Errorf("Keys() = %v, want [only]", got)
+	}
+}
+
+func TestContains(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	for i := 1; i <= 3; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	if !cache.Contains("key1") || cache.Contains("missing") {
+		t.Error("Contains should report key1 only")
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Contains counted as a Get: %+v", stats)
+	}
+
+	// key1 is still the tail, the next insertion evicts it
+	cache.Set("key4", "value")
+	if cache.Contains("key1") {
+		t.Error("Contains should not have saved key1 from eviction")
+	}
+	want := []string{"key4", "key3", "key2"}
+	if got := listKeys(cache); !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	if value, ok := cache.GetAndDelete("missing"); ok || value != "" {
+		t.Errorf("GetAndDelete(missing) = (%q, %v), want (\"\", false)", value, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d after a missing GetAndDelete, want 2", cache.Len())
+	}
+
+	if value, ok := cache.GetAndDelete("key1"); !ok || value != "value1" {
+		t.Errorf("GetAndDelete(key1) = (%q, %v), want (value1, true)", value, ok)
+	}
+	if cache.Contains("key1") {
+		t.Error("key1 should be gone")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestGetOldestAndRemoveOldest(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	if _, _, ok := cache.GetOldest(); ok {
+		t.Error("GetOldest on an empty cache should report ok false")
+	}
+	if _, _, ok := cache.RemoveOldest(); ok {
+		t.Error("RemoveOldest on an empty cache should report ok false")
+	}
+
+	for i := 1; i <= 4; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	cache.Get("key1")
+	cache.Get("key2")
+
+	key, value, ok := cache.GetOldest()
+	if !ok || key != "key3" || value != "value3" {
+		t.Errorf("GetOldest() = (%v, %v, %v), want (key3, value3, true)", key, value, ok)
+	}
+	// reading it didn't promote it
+	if key, _, _ := cache.GetOldest(); key != "key3" {
+		t.Errorf("GetOldest() = %v after a GetOldest, want key3", key)
+	}
+
+	key, value, ok = cache.RemoveOldest()
+	if !ok || key != "key3" || value != "value3" {
+		t.Errorf("RemoveOldest() = (%v, %v, %v), want (key3, value3, true)", key, value, ok)
+	}
+	if cache.Len() != 3 {
+		t.Errorf("Len() = %d after RemoveOldest, want 3", cache.Len())
+	}
+	if key, _, _ := cache.GetOldest(); key != "key4" {
+		t.Errorf("GetOldest() = %v after RemoveOldest, want key4", key)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	for i := 1; i <= 4; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	var visited []string
+	cache.ForEach(func(key, value string) bool {
+		if value != "value"+strings.TrimPrefix(key, "key") {
+			t.Errorf("ForEach passed %s=%s", key, value)
+		}
+		visited = append(visited, key)
+		// the lock is released, using the cache doesn't deadlock
+		cache.Delete(key)
+		return len(visited) < 3
+	})
+
+	want := []string{"key4", "key3", "key2"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("ForEach visited %v, want %v", visited, want)
+	}
+	if got := cache.Keys(); !reflect.DeepEqual(got, []string{"key1"}) {
+		t.Errorf("Keys() = %v, want [key1]", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	for i := 1; i <= 3; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	if cache.Update("missing", func(old string) string {
+		t.Error("fn called for a missing key")
+		return old
+	}) {
+		t.Error("Update of a missing key should return false")
+	}
+	if cache.Contains("missing") {
+		t.Error("Update should not insert a missing key")
+	}
+
+	var seen string
+	if !cache.Update("key1", func(old string) string {
+		seen = old
+		return old + "+1"
+	}) {
+		t.Error("Update of key1 should return true")
+	}
+	if seen != "value1" {
+		t.Errorf("fn saw %q, want value1", seen)
+	}
+
+	// key1 was the tail, it is now the MRU and survives the next eviction
+	cache.Set("key4", "value4")
+	if value, ok := cache.Peek("key1"); !ok || value != "value1+1" {
+		t.Errorf("Peek(key1) = (%v, %v), want (value1+1, true)", value, ok)
+	}
+	if cache.Contains("key2") {
+		t.Error("key2 should have been evicted in place of key1")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
+
+// Promotion relinks the existing node: the store keeps the same node and Get allocates nothing
+func TestGetPromotesInPlace(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+
+	node := cache.store["key1"]
+	cache.Get("key1")
+	if cache.store["key1"] != node || cache.head != node {
+		t.Error("Get should move the existing node to the head")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		cache.Get("key2")
+		cache.Get("key3")
+	})
+	if allocs != 0 {
+		t.Errorf("Get allocated %v times per run, want 0", allocs)
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	clock := newFakeClock()
+	var audit strings.Builder
+	cache, _ := NewStringCache(5, WithClock(clock.Now), WithAuditLog(&audit))
+	cache.SetWithTTL("key1", "value1", time.Second)
+	cache.SetWithTTL("key2", "value2", time.Second)
+	cache.SetWithTTL("key3", "value3", time.Second)
+	clock.Advance(2 * time.Second)
+
+	// expired entries are missing for every kind of deletion
+	if CompareAndDelete(cache, "key1", "value1") {
+		t.Error("CompareAndDelete of an expired entry should report false")
+	}
+	if cache.Delete("key2") {
+		t.Error("Delete of an expired entry should report false")
+	}
+	if ok, cascaded := cache.DeleteWithDeps("key3"); ok || cascaded != 0 {
+		t.Errorf("DeleteWithDeps of an expired entry = (%v, %d), want (false, 0)", ok, cascaded)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d, the expired entries should be removed", cache.Len())
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if lines := strings.Count(audit.String(), " expired\n"); lines != 3 {
+		t.Errorf("audit log =\n%s\nwant 3 expired removals", audit.String())
+	}
+}
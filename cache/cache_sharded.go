@@ -0,0 +1,163 @@
+package cache
+
+import "fmt"
+
+// ShardedCache spreads its keys over independent LruCaches, each with its own mutex, so that
+// operations on different shards don't contend. Recency is tracked per shard: the entry evicted
+// is the LRU of the shard the new key falls in, not necessarily the LRU of the whole cache
+type ShardedCache[K comparable, V any] struct {
+	shards []*LruCache[K, V]
+}
+
+// NewShardedCache creates a cache of the given total capacity split across shards LruCaches.
+// The capacity is divided as evenly as possible, the first shards taking the remainder.
+// The options apply to every shard, except WithAuditLog whose writer is shared: the shards queue
+// their removals to a single goroutine writing them. WithWriteBehind is rejected, its dirty entries
+// are guarded by the mutex of a single cache
+func NewShardedCache[K comparable, V any](capacity, shards int, opts ...Option) (*ShardedCache[K, V], error) {
+	if shards <= 0 {
+		return nil, fmt.Errorf("shards must be greater than 0")
+	}
+	if capacity < shards {
+		return nil, fmt.Errorf("capacity must be at least the number of shards, got %d for %d shards", capacity, shards)
+	}
+	config := defaultOptions()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.writeBehindFlush != nil {
+		return nil, fmt.Errorf("write-behind is not supported by a sharded cache")
+	}
+
+	var audit *auditLog
+	if config.auditLog != nil {
+		audit = newAuditLog(config.auditLog, auditQueueSize)
+		// clipped, not to write into the spare capacity of the caller's slice
+		opts = append(opts[:len(opts):len(opts)], withSharedAudit(audit))
+	}
+
+	cache := &ShardedCache[K, V]{shards: make([]*LruCache[K, V], shards)}
+	for i := range cache.shards {
+		shardCapacity := capacity / shards
+		if i < capacity%shards {
+			shardCapacity++
+		}
+		shard, err := NewCache[K, V](shardCapacity, opts...)
+		if err != nil {
+			if audit != nil {
+				audit.close()
+			}
+			return nil, err
+		}
+		cache.shards[i] = shard
+	}
+	return cache, nil
+}
+
+// FNV-1a parameters, the hash is inlined rather than going through hash/fnv which allocates
+const (
+	fnvOffset32 = 2166136261
+	fnvPrime32  = 16777619
+)
+
+// shard routes key with its FNV-1a hash. Strings and integers are hashed directly, other keys
+// (including named integer types) through their Go-syntax representation
+func (cache *ShardedCache[K, V]) shard(key K) *LruCache[K, V] {
+	var hash uint32
+	switch k := any(key).(type) {
+	case string:
+		hash = fnvString(k)
+	case int:
+		hash = fnvUint64(uint64(k))
+	case int8:
+		hash = fnvUint64(uint64(k))
+	case int16:
+		hash = fnvUint64(uint64(k))
+	case int32:
+		hash = fnvUint64(uint64(k))
+	case int64:
+		hash = fnvUint64(uint64(k))
+	case uint:
+		hash = fnvUint64(uint64(k))
+	case uint8:
+		hash = fnvUint64(uint64(k))
+	case uint16:
+		hash = fnvUint64(uint64(k))
+	case uint32:
+		hash = fnvUint64(uint64(k))
+	case uint64:
+		hash = fnvUint64(k)
+	case uintptr:
+		hash = fnvUint64(uint64(k))
+	default:
+		hash = fnvString(fmt.Sprintf("%#v", key))
+	}
+	return cache.shards[hash%uint32(len(cache.shards))]
+}
+
+// fnvString returns the FNV-1a hash of the bytes of s
+func fnvString(s string) uint32 {
+	hash := uint32(fnvOffset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= fnvPrime32
+	}
+	return hash
+}
+
+// fnvUint64 returns the FNV-1a hash of the 8 little-endian bytes of v
+func fnvUint64(v uint64) uint32 {
+	hash := uint32(fnvOffset32)
+	for i := 0; i < 8; i++ {
+		hash ^= uint32(byte(v >> (8 * i)))
+		hash *= fnvPrime32
+	}
+	return hash
+}
+
+// Get retrieves a value from the shard of key, see LruCache.Get
+func (cache *ShardedCache[K, V]) Get(key K) (value V, ok bool) {
+	return cache.shard(key).Get(key)
+}
+
+// Set adds or updates a key-value pair in the shard of key, see LruCache.Set
+func (cache *ShardedCache[K, V]) Set(key K, value V) (updated bool) {
+	return cache.shard(key).Set(key, value)
+}
+
+// Delete removes key from its shard, see LruCache.Delete
+func (cache *ShardedCache[K, V]) Delete(key K) (ok bool) {
+	return cache.shard(key).Delete(key)
+}
+
+// Len returns the number of entries across all shards.
+// Shards are counted one after the other, concurrent writes may be partly counted
+func (cache *ShardedCache[K, V]) Len() (length int) {
+	for _, shard := range cache.shards {
+		length += shard.Len()
+	}
+	return length
+}
+
+// Close closes every shard, see LruCache.Close. It returns the first error met, after closing
+// all of them
+func (cache *ShardedCache[K, V]) Close() error {
+	var err error
+	for _, shard := range cache.shards {
+		if shardErr := shard.Close(); err == nil {
+			err = shardErr
+		}
+	}
+	return err
+}
+
+// ShardStats returns the Stats of every shard, in shard order. Comparing them shows a hot shard,
+// the sign of keys spreading poorly. Shards are read one after the other, concurrent operations
+// may be counted in some of them only
+func (cache *ShardedCache[K, V]) ShardStats() []CacheStats {
+	stats := make([]CacheStats, len(cache.shards))
+	for i, shard := range cache.shards {
+		stats[i] = shard.Stats()
+	}
+	return stats
+}
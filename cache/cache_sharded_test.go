@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedCacheCapacity(t *testing.T) {
+	if _, err := NewShardedCache[string, string](10, 0); err == nil {
+		t.Error("expected an error for 0 shards")
+	}
+	if _, err := NewShardedCache[string, string](3, 4); err == nil {
+		t.Error("expected an error for fewer slots than shards")
+	}
+
+	cache, err := NewShardedCache[string, string](10, 4)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	total := 0
+	for i, shard := range cache.shards {
+		want := 2
+		if i < 2 {
+			want = 3
+		}
+		if shard.capacity != want {
+			t.Errorf("shard %d capacity = %d, want %d", i, shard.capacity, want)
+		}
+		total += shard.capacity
+	}
+	if total != 10 {
+		t.Errorf("total capacity = %d, want 10", total)
+	}
+}
+
+func TestShardedCacheOperations(t *testing.T) {
+	cache, _ := NewShardedCache[string, string](100, 4)
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	if cache.Len() != 50 {
+		t.Errorf("Len() = %d, want 50", cache.Len())
+	}
+
+	// keys are routed consistently
+	for i := 0; i < 50; i++ {
+		value, ok := cache.Get(fmt.Sprintf("key%d", i))
+		if !ok || value != fmt.Sprintf("value%d", i) {
+			t.Errorf("Get(key%d) = %q, %v", i, value, ok)
+		}
+	}
+	if !cache.Set("key0", "updated") {
+		t.Error("Set on an existing key should report an update")
+	}
+	if !cache.Delete("key1") || cache.Delete("key1") {
+		t.Error("Delete should succeed once")
+	}
+	if _, ok := cache.Get("key1"); ok {
+		t.Error("key1 should be gone")
+	}
+	if cache.Len() != 49 {
+		t.Errorf("Len() = %d, want 49", cache.Len())
+	}
+
+	// each shard stays within its own capacity
+	for i := 50; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	for i, shard := range cache.shards {
+		if shard.Len() > shard.capacity {
+			t.Errorf("shard %d holds %d entries for a capacity of %d", i, shard.Len(), shard.capacity)
+		}
+	}
+}
+
+func TestShardedCacheConcurrency(t *testing.T) {
+	cache, _ := NewShardedCache[string, string](20, 4)
+	var wg sync.WaitGroup
+	for j := 0; j < 50; j++ {
+		wg.Add(1)
+		go func(routineNum int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("key%d", i%40)
+				if (i+routineNum)%2 == 0 {
+					cache.Set(key, "value")
+				} else {
+					cache.Get(key)
+				}
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	for i, shard := range cache.shards {
+		if err := verifyIntegrity(shard); err != nil {
+			t.Errorf("shard %d: %v", i, err)
+		}
+	}
+}
+
+func TestShardedCacheIntegerKeys(t *testing.T) {
+	cache, _ := NewShardedCache[int, string](100, 4)
+	for i := 0; i < 100; i++ {
+		cache.Set(i, fmt.Sprint(i))
+	}
+	// consecutive integers spread over every shard
+	for i, shard := range cache.shards {
+		if shard.Len() == 0 {
+			t.Errorf("shard %d holds no integer key", i)
+		}
+	}
+	for i := 0; i < 100; i += 10 {
+		if value, ok := cache.Get(i); !ok || value != fmt.Sprint(i) {
+			t.Errorf("Get(%d) = (%q, %v)", i, value, ok)
+		}
+	}
+}
+
+func TestShardedCacheOptions(t *testing.T) {
+	store := newBackingStore(0)
+	if _, err := NewShardedCache[string, string](10, 2, WithWriteBehind(store.flush, time.Second, 10)); err == nil {
+		t.Error("NewShardedCache with write-behind should fail")
+	}
+
+	goroutines := runtime.NumGoroutine()
+	var out bytes.Buffer
+	cache, err := NewShardedCache[string, string](4, 4, WithAuditLog(&out))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	var wg sync.WaitGroup
+	for j := 0; j < 4; j++ {
+		wg.Add(1)
+		go func(routineNum int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("key%d-%d", routineNum, i)
+				cache.Set(key, "value")
+				cache.Delete(key)
+			}
+		}(j)
+	}
+	wg.Wait()
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// the shards wrote to a single log, every removal made it through
+	if lines := strings.Count(out.String(), "\n"); lines != 200 {
+		t.Errorf("audit log holds %d lines, want 200", lines)
+	}
+	if !waitFor(time.Second, func() bool { return runtime.NumGoroutine() <= goroutines }) {
+		t.Errorf("%d goroutines running after Close, %d before the cache was created", runtime.NumGoroutine(), goroutines)
+	}
+}
+
+func TestShardStats(t *testing.T) {
+	cache, _ := NewShardedCache[string, string](400, 4)
+	for i := 0; i < 400; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	// a balanced load spreads the hits evenly
+	for i := 0; i < 400; i++ {
+		cache.Get(fmt.Sprintf("key%d", i))
+	}
+	stats := cache.ShardStats()
+	if len(stats) != 4 {
+		t.Fatalf("ShardStats() returned %d shards, want 4", len(stats))
+	}
+	total := 0
+	for i, shard := range stats {
+		total += int(shard.Hits)
+		if shard.Hits < 50 || shard.Hits > 150 {
+			t.Errorf("shard %d counted %d of 400 balanced hits", i, shard.Hits)
+		}
+		if shard.Len != int(shard.Hits) {
+			t.Errorf("shard %d holds %d entries for %d hits", i, shard.Len, shard.Hits)
+		}
+	}
+	if total != 400 {
+		t.Errorf("shards counted %d hits, want 400", total)
+	}
+
+	// a skewed load makes the shard of the hot key stand out
+	for _, shard := range cache.shards {
+		shard.ResetStats()
+	}
+	for i := 0; i < 1000; i++ {
+		cache.Get("key0")
+	}
+	for i := 0; i < 40; i++ {
+		cache.Get(fmt.Sprintf("key%d", i))
+	}
+	hot := cache.shard("key0")
+	for i, shard := range cache.ShardStats() {
+		if cache.shards[i] == hot {
+			if shard.Hits < 1000 {
+				t.Errorf("hot shard %d counted %d hits, want at least 1000", i, shard.Hits)
+			}
+		} else if shard.Hits > 40 {
+			t.Errorf("shard %d counted %d hits, want at most 40", i, shard.Hits)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// A size cache bounds the bytes of its entries rather than their count. The size of an entry is
+// the length of its key plus the length of its value, the running total is kept up to date on
+// insertion, update and removal so that checking the budget is O(1). Only the bytes of string
+// and []byte keys and values are counted, see contentBytes
+
+// ErrEntryTooLarge is returned by TrySet for an entry larger than the byte budget of the cache
+var ErrEntryTooLarge = errors.New("entry larger than the cache byte budget")
+
+// NewSizeCache creates a StringCache evicting LRU entries once the keys and values it holds total
+// more than maxBytes, whatever their count: Capacity reports math.MaxInt.
+// Prefix fairness shares an entry count, it can't be combined with a byte budget
+func NewSizeCache(maxBytes int64, opts ...Option) (*StringCache, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("maxBytes must be greater than 0")
+	}
+	cache, err := NewStringCache(math.MaxInt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if cache.fairness != nil {
+		return nil, fmt.Errorf("prefix fairness requires an entry count capacity")
+	}
+	cache.maxBytes = maxBytes
+	return cache, nil
+}
+
+// entrySize returns the bytes an entry counts for against the budget
+func entrySize[K comparable, V any](key K, value V) int64 {
+	return int64(contentBytes(key) + contentBytes(value))
+}
+
+// fits reports whether an entry of key and value can be stored at all
+func (cache *LruCache[K, V]) fits(key K, value V) bool {
+	return cache.maxBytes == 0 || entrySize(key, value) <= cache.maxBytes
+}
+
+// overBudget reports whether the cache holds more than its capacity or byte budget allow,
+// room for size more bytes included
+func (cache *LruCache[K, V]) overBudget(size int64) bool {
+	if len(cache.store) > cache.capacity {
+		return true
+	}
+	return cache.maxBytes > 0 && cache.currentBytes+size > cache.maxBytes
+}
+
+// TrySet is Set reporting ErrEntryTooLarge instead of silently dropping an entry larger than the
+// byte budget. An existing entry of key is left untouched in that case
+func (cache *LruCache[K, V]) TrySet(key K, value V) (updated bool, err error) {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.unlockAndNotify()
+	defer cache.debugCheck()
+
+	if !cache.fits(key, value) {
+		return false, fmt.Errorf("setting key %v: %w", key, ErrEntryTooLarge)
+	}
+	return cache.set(key, value), nil
+}
+
+// Bytes returns the total size of the keys and values held, as counted against the byte budget
+// of a NewSizeCache. Other caches count it too, for the bytes of their string keys and values
+func (cache *LruCache[K, V]) Bytes() int64 {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.currentBytes
+}
+
+// FreeBytes returns how many bytes of keys and values can be inserted before eviction starts in a
+// NewSizeCache, 0 once its budget is used (or exceeded while eviction is suspended).
+// Other caches have no byte budget, FreeBytes returns -1 for them: see FreeSlots
+func (cache *LruCache[K, V]) FreeBytes() int64 {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.maxBytes == 0 {
+		return -1
+	}
+	return max(cache.maxBytes-cache.currentBytes, 0)
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSizeCacheAccounting(t *testing.T) {
+	if _, err := NewSizeCache(0); err == nil {
+		t.Error("expected an error for a budget of 0")
+	}
+	if _, err := NewSizeCache(100, WithPrefixFairness(tenantOf, 0.5)); err == nil {
+		t.Error("expected an error combining a byte budget with prefix fairness")
+	}
+
+	cache, err := NewSizeCache(20)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	checkBytes := func(step string, want int64) {
+		t.Helper()
+		if got := cache.Bytes(); got != want {
+			t.Errorf("%s: Bytes() = %d, want %d", step, got, want)
+		}
+		if err := verifyIntegrity(cache); err != nil {
+			t.Errorf("%s: integrity check failed: %v", step, err)
+		}
+	}
+
+	cache.Set("a", "12345") // 6 bytes
+	cache.Set("b", "1234")  // 5 bytes
+	checkBytes("insertions", 11)
+	if cache.FreeBytes() != 9 || cache.FreeSlots() != -1 {
+		t.Errorf("FreeBytes() = %d, FreeSlots() = %d, want 9 and -1", cache.FreeBytes(), cache.FreeSlots())
+	}
+
+	cache.Set("a", "1") // 2 bytes
+	checkBytes("update", 7)
+
+	cache.Delete("b")
+	checkBytes("delete", 2)
+
+	cache.Set("c", "123456789") // 10 bytes
+	cache.Set("d", "12345678")  // 9 bytes, evicts a
+	checkBytes("eviction", 19)
+	if want := []string{"d", "c"}; !reflect.DeepEqual(cache.Keys(), want) {
+		t.Errorf("Keys() = %v, want %v", cache.Keys(), want)
+	}
+
+	// an update growing past the budget evicts others, never itself
+	cache.Set("d", strings.Repeat("x", 19))
+	checkBytes("growing update", 20)
+	if want := []string{"d"}; !reflect.DeepEqual(cache.Keys(), want) {
+		t.Errorf("Keys() = %v, want %v", cache.Keys(), want)
+	}
+
+	cache.Clear()
+	checkBytes("clear", 0)
+}
+
+func TestSizeCacheEntryTooLarge(t *testing.T) {
+	cache, _ := NewSizeCache(10)
+	cache.Set("key", "value")
+
+	if _, err := cache.TrySet("key", "much too long"); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("TrySet() error = %v, want %v", err, ErrEntryTooLarge)
+	}
+	if cache.Set("other", "much too long") {
+		t.Error("Set of a too large entry should not report an update")
+	}
+	// variants of Set refuse it the same way
+	cache.SetWithTTL("other", "much too long", time.Minute)
+	cache.SetWithPriority("other", "much too long", 1)
+	cache.SetWithDeps("other", "much too long", []string{"key"})
+	if err := SetReader(cache, "other", strings.NewReader("much too long"), 13); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("SetReader() error = %v, want %v", err, ErrEntryTooLarge)
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+
+	// the previous entry is kept, nothing was evicted for the refused ones
+	if value, ok := cache.Get("key"); !ok || value != "value" {
+		t.Errorf("Get(key) = (%v, %v), want (value, true)", value, ok)
+	}
+	if cache.Len() != 1 || cache.Bytes() != 8 {
+		t.Errorf("Len() = %d, Bytes() = %d, want 1 and 8", cache.Len(), cache.Bytes())
+	}
+
+	if updated, err := cache.TrySet("key", "v2"); err != nil || !updated {
+		t.Errorf("TrySet() = (%v, %v), want (true, nil)", updated, err)
+	}
+}
+
+func TestSizeCacheRefusedVariantsKeepEntry(t *testing.T) {
+	clock := newFakeClock()
+	cache, _ := NewSizeCache(20, WithClock(clock.Now))
+	cache.Set("parent", "p")
+	cache.Set("key", "v")
+	tooLarge := strings.Repeat("x", 100)
+
+	// a refused value leaves the existing entry of key as it was
+	if cache.SetWithTTL("key", tooLarge, time.Millisecond) {
+		t.Error("SetWithTTL of a too large entry should not report an update")
+	}
+	clock.Advance(time.Second)
+	if value, ok := cache.Get("key"); !ok || value != "v" {
+		t.Errorf("Get(key) after a refused SetWithTTL = (%v, %v), want (v, true)", value, ok)
+	}
+
+	if cache.SetWithPriority("key", tooLarge, -3) {
+		t.Error("SetWithPriority of a too large entry should not report an update")
+	}
+	if priority, ok := cache.Priority("key"); !ok || priority != 0 {
+		t.Errorf("Priority(key) after a refused SetWithPriority = (%v, %v), want (0, true)", priority, ok)
+	}
+
+	if cache.SetWithDeps("key", tooLarge, []string{"parent"}) {
+		t.Error("SetWithDeps of a too large entry should not report an update")
+	}
+	if ok, cascaded := cache.DeleteWithDeps("parent"); !ok || cascaded != 0 {
+		t.Errorf("DeleteWithDeps(parent) = (%v, %d), want (true, 0)", ok, cascaded)
+	}
+	if !cache.Contains("key") {
+		t.Error("key should not depend on parent after a refused SetWithDeps")
+	}
+	if err := verifyIntegrity(cache); err != nil {
+		t.Errorf("integrity check failed: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats is a snapshot of the counters of a cache, see Stats
+type CacheStats struct {
+	// Gets that found their key, and Gets that missed
+	Hits   uint64
+	Misses uint64
+	// entries removed to make room for another one
+	Evictions uint64
+	// entries in the cache and its capacity when the snapshot was taken
+	Len      int
+	Capacity int
+}
+
+// cacheStats holds the counters behind Stats, updated under the cache lock. Gets answered under
+// the read lock count concurrently: hits and misses are added atomically, the write lock
+// excludes those adds so it may read and reset them plainly
+type cacheStats struct {
+	hits, misses, evictions uint64
+}
+
+// countGet counts a Get as a hit or a miss
+func (cache *LruCache[K, V]) countGet(hit bool) {
+	if hit {
+		atomic.AddUint64(&cache.stats.hits, 1)
+	} else {
+		atomic.AddUint64(&cache.stats.misses, 1)
+	}
+}
+
+// countEviction counts the removal if it was a capacity eviction
+func (cache *LruCache[K, V]) countEviction(reason RemovalReason) {
+	if reason == ReasonEvicted {
+		cache.stats.evictions++
+	}
+}
+
+// Stats returns the hit, miss and eviction counts since the cache was created or since the last
+// ResetStats, along with the current number of entries and capacity. Every read path counts (Get, GetCtx,
+// GetByIndex...) except reads served by a future, and except Peek which doesn't count as an access
+func (cache *LruCache[K, V]) Stats() CacheStats {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return CacheStats{
+		Hits:      cache.stats.hits,
+		Misses:    cache.stats.misses,
+		Evictions: cache.stats.evictions,
+		Len:       len(cache.store),
+		Capacity:  cache.capacity,
+	}
+}
+
+// ResetStats sets the hit, miss and eviction counts back to zero, to sample them over a window
+func (cache *LruCache[K, V]) ResetStats() {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.stats = cacheStats{}
+}
+
+// EstimatedSavings approximates the time the cache saved: every hit spared a miss costing
+// perMissCost. It counts the hits since the cache was created or since the last ResetStats
+func (cache *LruCache[K, V]) EstimatedSavings(perMissCost time.Duration) time.Duration {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return time.Duration(cache.stats.hits) * perMissCost
+}
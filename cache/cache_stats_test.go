@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	cache.Get("key1")            // hit
+	cache.Get("key2")            // hit
+	cache.Get("missing")         // miss
+	cache.Set("key3", "value3")  // evicts key1
+	cache.Get("key1")            // miss
+	cache.Peek("key3")           // not counted
+	cache.Set("key2", "updated") // update, no eviction
+	cache.Delete("key3")         // deletion, not an eviction
+
+	want := CacheStats{Hits: 2, Misses: 2, Evictions: 1, Len: 1, Capacity: 2}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	cache.ResetStats()
+	cache.Get("key2")
+	want = CacheStats{Hits: 1, Len: 1, Capacity: 2}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() after ResetStats = %+v, want %+v", got, want)
+	}
+}
+
+func TestEstimatedSavings(t *testing.T) {
+	cache, _ := NewStringCache(2)
+	cache.Set("key1", "value1")
+	for i := 0; i < 3; i++ {
+		cache.Get("key1")
+	}
+	cache.Get("missing") // misses save nothing
+
+	if got := cache.EstimatedSavings(40 * time.Millisecond); got != 120*time.Millisecond {
+		t.Errorf("EstimatedSavings(40ms) = %v, want 120ms", got)
+	}
+	cache.ResetStats()
+	if got := cache.EstimatedSavings(time.Second); got != 0 {
+		t.Errorf("EstimatedSavings(1s) after ResetStats = %v, want 0", got)
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+import "context"
+
+// Stream emits the entries from the most to the least recently used on the returned channel.
+// The entries are snapshotted under the lock, then sent without holding it: the cache stays usable
+// while the stream is consumed and later changes are not reflected.
+// The channel is closed once every entry is sent or as soon as ctx is done
+func (cache *LruCache[K, V]) Stream(ctx context.Context) <-chan Entry[K, V] {
+	// protect DS
+	cache.mutex.Lock()
+	entries := cache.entries()
+	cache.mutex.Unlock()
+
+	stream := make(chan Entry[K, V])
+	go func() {
+		defer close(stream)
+		for _, entry := range entries {
+			// select picks randomly among ready cases, don't keep sending to an eager reader once cancelled
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case stream <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return stream
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+	cache.Get("key1")
+
+	stream := cache.Stream(context.Background())
+	// the snapshot is already taken
+	cache.Set("key4", "value4")
+
+	var got []Entry[string, string]
+	for entry := range stream {
+		got = append(got, entry)
+	}
+	want := []Entry[string, string]{{"key1", "value1"}, {"key3", "value3"}, {"key2", "value2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Stream() = %v, want %v", got, want)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Set("key3", "value3")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := cache.Stream(ctx)
+	if entry := <-stream; entry.Key != "key3" {
+		t.Errorf("first streamed entry = %v, want key3", entry)
+	}
+	cancel()
+
+	// the producer stops and closes the channel, nothing more is sent
+	received := 0
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, open := <-stream:
+			if !open {
+				if received > 0 {
+					t.Errorf("received %d entries after cancellation, want 0", received)
+				}
+				return
+			}
+			received++
+		case <-timeout:
+			t.Fatal("stream not closed after cancellation")
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"slices"
+	"time"
+)
+
+// EvictionRecord describes a removal the cache decided on its own
+type EvictionRecord[K comparable] struct {
+	Key    K
+	Reason RemovalReason
+	At     time.Time
+}
+
+// evictionTrace keeps every eviction decision, unbounded, see WithEvictionRecorder
+type evictionTrace[K comparable] struct {
+	records []EvictionRecord[K]
+}
+
+// WithEvictionRecorder enables EvictionTrace by recording every eviction decision: capacity
+// evictions, expirations and cascades, but not the deletions asked for by the caller.
+// The trace grows without bound, it is meant for tests and debugging of eviction policies
+func WithEvictionRecorder() Option {
+	return func(o *options) {
+		o.evictionRecorder = true
+	}
+}
+
+// recordTrace adds the removal of key to the trace unless it was a deletion
+func (cache *LruCache[K, V]) recordTrace(key K, reason RemovalReason) {
+	if cache.trace != nil && reason != ReasonDeleted {
+		cache.trace.records = append(cache.trace.records, EvictionRecord[K]{Key: key, Reason: reason, At: cache.now()})
+	}
+}
+
+// EvictionTrace returns a copy of the eviction decisions recorded so far, oldest first.
+// It always returns nil unless the cache was created WithEvictionRecorder
+func (cache *LruCache[K, V]) EvictionTrace() []EvictionRecord[K] {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.trace == nil {
+		return nil
+	}
+	return slices.Clone(cache.trace.records)
+}
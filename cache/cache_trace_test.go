@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvictionTrace(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	cache, _ := NewStringCache(2, WithClock(clock.Now), WithMaxIdle(time.Hour), WithEvictionRecorder())
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Get("key1")
+	clock.Advance(time.Minute)
+	cache.Set("key3", "value3")                           // evicts key2, the LRU
+	cache.SetWithDeps("child", "value", []string{"key3"}) // evicts key1
+	clock.Advance(time.Minute)
+	cache.Set("key4", "value4") // evicts key3 and its dependent child
+	cache.Delete("key4")        // deletions are not recorded
+	cache.Set("key5", "value5")
+	clock.Advance(2 * time.Hour)
+	cache.Get("key5") // idle for too long
+
+	want := []EvictionRecord[string]{
+		{"key2", ReasonEvicted, start.Add(time.Minute)},
+		{"key1", ReasonEvicted, start.Add(time.Minute)},
+		{"key3", ReasonEvicted, start.Add(2 * time.Minute)},
+		{"child", ReasonCascade, start.Add(2 * time.Minute)},
+		{"key5", ReasonExpired, start.Add(2*time.Minute + 2*time.Hour)},
+	}
+	if got := cache.EvictionTrace(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EvictionTrace() = %v, want %v", got, want)
+	}
+
+	untraced, _ := NewStringCache(1)
+	untraced.Set("key1", "value1")
+	untraced.Set("key2", "value2")
+	if got := untraced.EvictionTrace(); got != nil {
+		t.Errorf("EvictionTrace() without recorder = %v, want nil", got)
+	}
+}
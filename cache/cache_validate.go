@@ -0,0 +1,200 @@
+package cache
+
+import "fmt"
+
+// The circular-doubly linked list has many moving parts, pointers and links
+// this methods attempts to check all aspects of the implementation internals
+// for incositencies
+
+// validate checks for the following:
+//   - cohesion between the LruCache instance and the underlying data structure
+//   - cohesion between linked list and hashmap
+//   - edge cases: empty cache, single node,
+func (cache *LruCache[K, V]) validate() error {
+	// check empty cache
+	if len(cache.store) == 0 {
+		if cache.head != nil {
+			return fmt.Errorf("empty cache should have nil head, got non-nil")
+		}
+		return nil
+	}
+
+	// check size constraints, the cache is allowed to overflow while eviction is suspended
+	if len(cache.store) > cache.capacity && !cache.evictionSuspended {
+		return fmt.Errorf("cache size %d exceeds capacity %d", len(cache.store), cache.capacity)
+	}
+
+	// check the byte budget, and the running total against the entries
+	var bytes int64
+	for key, node := range cache.store {
+		bytes += entrySize(key, node.value)
+	}
+	if bytes != cache.currentBytes {
+		return fmt.Errorf("%d bytes counted, entries hold %d", cache.currentBytes, bytes)
+	}
+	if cache.maxBytes > 0 && bytes > cache.maxBytes && !cache.evictionSuspended {
+		return fmt.Errorf("cache holds %d bytes, exceeds budget of %d", bytes, cache.maxBytes)
+	}
+
+	// verify circular list integrity
+	nodeCount := 0
+	visited := make(map[*cacheNode[K, V]]bool)
+	current := cache.head
+
+	// circualr dll traversal
+	for {
+		// shoduldn't have nil nodes
+		if current == nil {
+			return fmt.Errorf("Unexpected nil, cache is not empty")
+		}
+
+		// Check for cycles (should complete exactly one cycle)
+		if visited[current] {
+			if nodeCount != len(cache.store) {
+				return fmt.Errorf("circular list size (%d) doesn't match store size (%d)", nodeCount, len(cache.store))
+			}
+			break
+		}
+
+		// shouldn't have nil pointers in non-empty cache
+		if current.next == nil || current.prev == nil {
+			return fmt.Errorf("node has nil pointer: next=%p, prev=%p", current.next, current.prev)
+		}
+
+		// checking order and pointer correctness
+		if current.next.prev != current {
+			return fmt.Errorf("broken bidirectional link: node.next.prev != node")
+		}
+		if current.prev.next != current {
+			return fmt.Errorf("broken bidirectional link: node.prev.next != node")
+		}
+
+		// verify node exists in store
+		storeNode, exists := cache.store[current.key]
+		if !exists {
+			return fmt.Errorf("node with key %v exists in list but not in store", current.key)
+		}
+		if storeNode != current {
+			return fmt.Errorf("store points to different node for key %v", current.key)
+		}
+
+		visited[current] = true
+		nodeCount++
+		current = current.next
+
+		// Safety check for infinite loops
+		if nodeCount > len(cache.store) {
+			return fmt.Errorf("circular list appears to have more nodes than store")
+		}
+	}
+
+	// Verify all store entries are in the list
+	for key, node := range cache.store {
+		if !visited[node] {
+			return fmt.Errorf("node for key %v exists in store but not in list", key)
+		}
+	}
+
+	// dependency index should only reference live dependents, in both directions
+	for key, parents := range cache.dependsOn {
+		if _, exists := cache.store[key]; !exists {
+			return fmt.Errorf("dependency index references removed key %v", key)
+		}
+		for _, parent := range parents {
+			if _, exists := cache.dependents[parent][key]; !exists {
+				return fmt.Errorf("key %v depends on %v but is missing from its dependents", key, parent)
+			}
+		}
+	}
+
+	// per priority counts should match the live entries
+	priorities := make(map[int]int)
+	for _, node := range cache.store {
+		if node.priority != 0 {
+			priorities[node.priority]++
+		}
+	}
+	if len(priorities) != len(cache.priorities) {
+		return fmt.Errorf("%d priorities tracked, store has %d", len(cache.priorities), len(priorities))
+	}
+	for priority, count := range priorities {
+		if cache.priorities[priority] != count {
+			return fmt.Errorf("priority %d counted %d times, store has %d", priority, cache.priorities[priority], count)
+		}
+	}
+
+	// per prefix counts should match the live entries
+	if cache.fairness != nil {
+		counts := make(map[string]int)
+		for key := range cache.store {
+			counts[cache.fairness.prefixOf(key)]++
+		}
+		if len(counts) != len(cache.fairness.counts) {
+			return fmt.Errorf("fairness tracks %d prefixes, store has %d", len(cache.fairness.counts), len(counts))
+		}
+		for prefix, count := range counts {
+			if cache.fairness.counts[prefix] != count {
+				return fmt.Errorf("prefix %s counted %d times, store has %d", prefix, cache.fairness.counts[prefix], count)
+			}
+		}
+	}
+
+	// intern table should reference exactly the values held by the entries
+	if cache.interned != nil {
+		refs := make(map[string]int)
+		for _, node := range cache.store {
+			refs[any(node.value).(string)]++
+		}
+		if len(refs) != len(cache.interned.values) {
+			return fmt.Errorf("intern table holds %d values, entries hold %d", len(cache.interned.values), len(refs))
+		}
+		for value, count := range refs {
+			if interned, ok := cache.interned.values[value]; !ok || interned.refs != count {
+				return fmt.Errorf("interned value %q has a wrong reference count", value)
+			}
+		}
+	}
+
+	// secondary index should only point to live entries holding a matching value
+	if cache.index != nil {
+		for indexKey, key := range cache.index.keys {
+			node, ok := cache.store[key]
+			if !ok {
+				return fmt.Errorf("secondary index %q points to missing key %v", indexKey, key)
+			}
+			if cache.index.extract(node.value) != indexKey {
+				return fmt.Errorf("secondary index %q points to key %v with a non matching value", indexKey, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the internal consistency of the cache and returns the first problem found.
+// It walks every entry, use it to debug or in tests rather than on a hot path
+func (cache *LruCache[K, V]) Validate() error {
+	// protect DS
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.validate()
+}
+
+// WithDebugIntegrityChecks validates the cache after every Get, Set and Delete and panics on the
+// first inconsistency. Every operation becomes O(n), it is meant for development builds only
+func WithDebugIntegrityChecks() Option {
+	return func(o *options) {
+		o.debugChecks = true
+	}
+}
+
+// debugCheck panics if debug checks are enabled and the cache is inconsistent
+func (cache *LruCache[K, V]) debugCheck() {
+	if !cache.debugChecks {
+		return
+	}
+	if err := cache.validate(); err != nil {
+		panic(fmt.Sprintf("cache integrity check failed: %v", err))
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugIntegrityChecks(t *testing.T) {
+	cache, _ := NewStringCache(5, WithDebugIntegrityChecks())
+
+	// a regular workload never trips the checks
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("key%d", i%8)
+		switch i % 3 {
+		case 0:
+			cache.Set(key, "value")
+		case 1:
+			cache.Get(key)
+		case 2:
+			cache.Delete(key)
+		}
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestDebugIntegrityChecksCorrupted(t *testing.T) {
+	cache, _ := NewStringCache(5, WithDebugIntegrityChecks())
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+
+	// break the DLL behind the cache's back
+	delete(cache.store, "key1")
+	if err := cache.Validate(); err == nil {
+		t.Fatal("Validate() should report the corruption")
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Get on a corrupted cache should panic with debug checks enabled")
+		}
+		if msg, ok := r.(string); !ok || !strings.Contains(msg, "integrity check failed") {
+			t.Errorf("unexpected panic value: %v", r)
+		}
+	}()
+	cache.Get("key2")
+}
+
+func TestNoDebugIntegrityChecks(t *testing.T) {
+	cache, _ := NewStringCache(5)
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	delete(cache.store, "key1")
+
+	// without the option the corruption goes unnoticed
+	cache.Get("key2")
+}
+
+// mustPanic runs fn and reports whether it panicked
+func mustPanic(fn func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	fn()
+	return false
+}
+
+func TestDebugCheckReleasesLock(t *testing.T) {
+	cache, _ := NewStringCache(5, WithDebugIntegrityChecks())
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	delete(cache.store, "key1")
+
+	if !mustPanic(func() { cache.Get("key2") }) {
+		t.Fatal("Get on a corrupted cache should panic with debug checks enabled")
+	}
+	// the lock was released on the way out
+	done := make(chan struct{})
+	go func() {
+		cache.Len()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Len blocked after a failed debug check: the lock is still held")
+	}
+}
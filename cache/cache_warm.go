@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WarmFromAccessLog replays a log of key accesses, one key per line from the oldest to the most
+// recent, so the cache starts with the entries and the LRU order the log leads to.
+// Values are fetched with fetch, keys it doesn't find are skipped. Only the most recent keys
+// the cache can hold survive a replay, so only those are fetched: the log is read backward
+// until enough keys were found. fetch is called without holding the lock.
+// It returns an error if the log can't be read, the cache is left untouched then.
+// The log holds keys as lines of text, so the cache must have string keys
+func WarmFromAccessLog[V any](cache *LruCache[string, V], r io.Reader, fetch func(key string) (V, bool)) error {
+	var accesses []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			accesses = append(accesses, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading access log: %w", err)
+	}
+
+	// the last access of each key decides its position, collect them from the most recent one
+	var entries []Entry[string, V]
+	seen := make(map[string]struct{})
+	for i := len(accesses) - 1; i >= 0 && len(entries) < cache.Capacity(); i-- {
+		key := accesses[i]
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if value, ok := fetch(key); ok {
+			entries = append(entries, Entry[string, V]{Key: key, Value: value})
+		}
+	}
+
+	// set from the least recent so the most recent ends up the head
+	for i := len(entries) - 1; i >= 0; i-- {
+		cache.Set(entries[i].Key, entries[i].Value)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWarmFromAccessLog(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	log := strings.Join([]string{"a", "b", "c", "gone", "a", "d", "", "b", "e", "b"}, "\n")
+
+	var fetched []string
+	err := WarmFromAccessLog(cache, strings.NewReader(log), func(key string) (string, bool) {
+		fetched = append(fetched, key)
+		if key == "gone" {
+			return "", false
+		}
+		return "value-" + key, true
+	})
+	if err != nil {
+		t.Fatalf("WarmFromAccessLog() error = %v", err)
+	}
+
+	// b, e and d are the three most recently accessed keys
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"b", "e", "d"}) {
+		t.Errorf("keys after warming = %v, want [b e d]", got)
+	}
+	if value, _ := cache.Get("e"); value != "value-e" {
+		t.Errorf("Get(e) = %v, want value-e", value)
+	}
+	// older keys are never fetched
+	if !reflect.DeepEqual(fetched, []string{"b", "e", "d"}) {
+		t.Errorf("fetched %v, want [b e d]", fetched)
+	}
+}
+
+func TestWarmFromAccessLogSkipsMissing(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	log := "a\nb\nc\ngone\n"
+	WarmFromAccessLog(cache, strings.NewReader(log), func(key string) (string, bool) {
+		return "value", key != "gone"
+	})
+	if got := listKeys(cache); !reflect.DeepEqual(got, []string{"c", "b", "a"}) {
+		t.Errorf("keys after warming = %v, want [c b a]", got)
+	}
+}
+
+func TestWarmFromAccessLogReadError(t *testing.T) {
+	cache, _ := NewStringCache(3)
+	failure := errors.New("disk error")
+	err := WarmFromAccessLog(cache, iotest.ErrReader(failure), func(key string) (string, bool) {
+		return "value", true
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("WarmFromAccessLog() error = %v, want %v", err, failure)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after a failed warm up, want 0", cache.Len())
+	}
+}
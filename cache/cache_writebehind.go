@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Write-behind keeps the values written by Set in a dirty map until they reach the backing store.
+// A background worker flushes the dirty entries in batches, on a fixed interval or as soon as a full
+// batch accumulated. A batch is taken out of the dirty map before being flushed, so the cache lock is
+// never held during the flush; if the flush fails its entries go back to the dirty map (unless they
+// were set again in the meantime) and are retried with the next flush.
+//
+// The dirty map is independent from the LRU list: evicting or deleting an entry doesn't cancel its
+// pending write, and deletions are not propagated to the backing store
+
+type writeBehind[K comparable, V any] struct {
+	flush    func(batch map[K]V) error
+	maxBatch int
+	dirty    map[K]V
+
+	// kick wakes the worker up when a batch is full
+	kick     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// WithWriteBehind passes every value written by Set to flush, asynchronously and in batches of
+// at most maxBatch entries. Batches are flushed every interval, or as soon as maxBatch entries are
+// pending. Failed batches are retried, Close flushes whatever is still pending.
+// The worker is a goroutine of its own, NewUnsafeCache rejects the option
+func WithWriteBehind[K comparable, V any](flush func(batch map[K]V) error, interval time.Duration, maxBatch int) Option {
+	return func(o *options) {
+		o.writeBehindFlush = flush
+		o.writeBehindInterval = interval
+		o.writeBehindBatch = maxBatch
+	}
+}
+
+func newWriteBehind[K comparable, V any](flush func(batch map[K]V) error, maxBatch int) *writeBehind[K, V] {
+	return &writeBehind[K, V]{
+		flush:    flush,
+		maxBatch: maxBatch,
+		dirty:    make(map[K]V),
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// runWriteBehind flushes dirty entries until the write-behind is stopped
+func (cache *LruCache[K, V]) runWriteBehind(interval time.Duration) {
+	wb := cache.writeBehind
+	defer close(wb.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-wb.kick:
+		case <-wb.stop:
+			return
+		}
+		// failed batches stay dirty and are retried on the next round
+		cache.flushDirty()
+	}
+}
+
+// markDirty records a value written to key as pending
+func (cache *LruCache[K, V]) markDirty(key K, value V) {
+	wb := cache.writeBehind
+	if wb == nil {
+		return
+	}
+
+	wb.dirty[key] = value
+	if len(wb.dirty) >= wb.maxBatch {
+		select {
+		case wb.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flushDirty flushes batches until nothing is pending or a flush fails
+func (cache *LruCache[K, V]) flushDirty() error {
+	wb := cache.writeBehind
+	for {
+		// protect DS
+		cache.mutex.Lock()
+		batch := make(map[K]V, min(len(wb.dirty), wb.maxBatch))
+		for key, value := range wb.dirty {
+			if len(batch) == wb.maxBatch {
+				break
+			}
+			batch[key] = value
+			delete(wb.dirty, key)
+		}
+		cache.mutex.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := wb.flush(batch); err != nil {
+			cache.mutex.Lock()
+			for key, value := range batch {
+				// a key set again during the flush already holds a newer value
+				if _, newer := wb.dirty[key]; !newer {
+					wb.dirty[key] = value
+				}
+			}
+			cache.mutex.Unlock()
+			return fmt.Errorf("write-behind flush: %w", err)
+		}
+	}
+}
+
+// stopWriteBehind stops the worker and flushes the remaining dirty entries
+func (cache *LruCache[K, V]) stopWriteBehind() error {
+	wb := cache.writeBehind
+	wb.stopOnce.Do(func() {
+		close(wb.stop)
+	})
+	<-wb.done
+	return cache.flushDirty()
+}
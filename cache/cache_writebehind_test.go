@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// backingStore records the batches it receives, failing the first failures ones
+type backingStore struct {
+	mutex    sync.Mutex
+	failures int
+	batches  []map[string]string
+	data     map[string]string
+}
+
+func newBackingStore(failures int) *backingStore {
+	return &backingStore{failures: failures, data: make(map[string]string)}
+}
+
+func (store *backingStore) flush(batch map[string]string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.failures > 0 {
+		store.failures--
+		return errors.New("backing store unavailable")
+	}
+	store.batches = append(store.batches, batch)
+	for key, value := range batch {
+		store.data[key] = value
+	}
+	return nil
+}
+
+func (store *backingStore) snapshot() (batches int, data map[string]string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	data = make(map[string]string, len(store.data))
+	for key, value := range store.data {
+		data[key] = value
+	}
+	return len(store.batches), data
+}
+
+func TestWriteBehindBatches(t *testing.T) {
+	store := newBackingStore(0)
+	cache, err := NewStringCache(3, WithWriteBehind(store.flush, time.Hour, 3))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	// more entries than the capacity: evicted entries still reach the store
+	want := make(map[string]string)
+	for i := 0; i < 7; i++ {
+		key := fmt.Sprintf("key%d", i)
+		cache.Set(key, fmt.Sprintf("value%d", i))
+		want[key] = fmt.Sprintf("value%d", i)
+	}
+	cache.Set("key6", "value6-updated")
+	want["key6"] = "value6-updated"
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	_, data := store.snapshot()
+	if fmt.Sprint(data) != fmt.Sprint(want) {
+		t.Errorf("backing store = %v, want %v", data, want)
+	}
+	for _, batch := range store.batches {
+		if len(batch) > 3 {
+			t.Errorf("batch of %d entries exceeds the maximum of 3", len(batch))
+		}
+	}
+}
+
+func TestWriteBehindInterval(t *testing.T) {
+	store := newBackingStore(0)
+	cache, _ := NewStringCache(10, WithWriteBehind(store.flush, 5*time.Millisecond, 100))
+	defer cache.Close()
+
+	cache.Set("key1", "value1")
+	flushed := waitFor(time.Second, func() bool {
+		_, data := store.snapshot()
+		return data["key1"] == "value1"
+	})
+	if !flushed {
+		t.Error("dirty entry was not flushed on the interval")
+	}
+}
+
+func TestWriteBehindRetry(t *testing.T) {
+	store := newBackingStore(2)
+	cache, _ := NewStringCache(10, WithWriteBehind(store.flush, 5*time.Millisecond, 2))
+	defer cache.Close()
+
+	for i := 0; i < 5; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), "value")
+	}
+
+	// the first two flushes fail, nothing is lost
+	flushed := waitFor(time.Second, func() bool {
+		_, data := store.snapshot()
+		return len(data) == 5
+	})
+	if !flushed {
+		_, data := store.snapshot()
+		t.Errorf("backing store holds %d entries after retries, want 5", len(data))
+	}
+}
+
+func TestWriteBehindCloseError(t *testing.T) {
+	store := newBackingStore(1)
+	cache, _ := NewStringCache(10, WithWriteBehind(store.flush, time.Hour, 10))
+	cache.Set("key1", "value1")
+
+	if err := cache.Close(); err == nil {
+		t.Error("Close should report the failed flush")
+	}
+	// the entry stayed dirty, closing again flushes it
+	if err := cache.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+	if _, data := store.snapshot(); data["key1"] != "value1" {
+		t.Errorf("backing store = %v, want key1 flushed", data)
+	}
+}
+
+func TestWriteBehindInvalidConfig(t *testing.T) {
+	store := newBackingStore(0)
+	if _, err := NewStringCache(10, WithWriteBehind(store.flush, 0, 10)); err == nil {
+		t.Error("NewCache with a zero interval should fail")
+	}
+	if _, err := NewStringCache(10, WithWriteBehind(store.flush, time.Second, 0)); err == nil {
+		t.Error("NewCache with a zero batch size should fail")
+	}
+	if _, err := NewUnsafeCache[string, string](10, WithWriteBehind(store.flush, time.Second, 10)); err == nil {
+		t.Error("NewUnsafeCache with write-behind should fail")
+	}
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"io"
+	"time"
+)
+
+// Option configures optional behaviour of a cache, it is passed to NewCache.
+// Every option is off by default so a plain NewCache(capacity) keeps the bare LRU behaviour
+type Option func(*options)
+
+// options collects the settings applied by Options before the cache is built.
+// Options don't know the key and value types of the cache, callbacks taking keys or values are
+// kept as any and checked against the types of the cache when it is built
+type options struct {
+	now                 func() time.Time
+	trackOpsRate        bool
+	refreshSeqOnUpdate  bool
+	onMiss              any // func(key K)
+	onEvict             any // func(key K, value V)
+	maxIdle             time.Duration
+	auditLog            io.Writer
+	sharedAudit         *auditLog // set by NewShardedCache in place of auditLog
+	evictionHistory     bool
+	debugChecks         bool
+	internValues        bool
+	indexExtract        any // func(value V) string
+	promotionBatch      int
+	lockHoldTracking    bool
+	uniqueKeyEstimation bool
+	evictionRecorder    bool
+	policy              Policy
+	lfuTieBreak         LFUTieBreak
+
+	writeBehindFlush    any // func(batch map[K]V) error
+	writeBehindInterval time.Duration
+	writeBehindBatch    int
+
+	prefixFn       any // func(key K) string
+	maxPrefixShare float64
+}
+
+func defaultOptions() options {
+	return options{
+		now:            time.Now,
+		promotionBatch: 1,
+	}
+}
+
+// WithClock replaces time.Now as the time source of the cache.
+// Every time-based feature reads the clock through it, which makes them testable with a fake clock
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithOpsRateTracking enables OpsPerSecond
+func WithOpsRateTracking() Option {
+	return func(o *options) {
+		o.trackOpsRate = true
+	}
+}
+
+// WithInsertionRefreshOnUpdate makes an update of an existing key count as a new insertion
+// for InsertionOrder. By default an updated key keeps its original insertion position
+func WithInsertionRefreshOnUpdate() Option {
+	return func(o *options) {
+		o.refreshSeqOnUpdate = true
+	}
+}
+
+// WithOnMiss registers a hook called with the key of every Get that misses.
+// It is a pure side effect: it runs after the lock is released, may call the cache,
+// and doesn't change what Get returns
+func WithOnMiss[K comparable](onMiss func(key K)) Option {
+	return func(o *options) {
+		o.onMiss = onMiss
+	}
+}
+
+// WithPromotionBatching only promotes an entry to MRU on every nth Get of it instead of every Get,
+// saving most of the list operations of a read heavy workload.
+// The order becomes an approximation of LRU: an entry read fewer than n times since its last
+// promotion ranks as if it hadn't been read, and may be evicted before entries read less recently.
+// Sets always promote
+func WithPromotionBatching(n int) Option {
+	return func(o *options) {
+		o.promotionBatch = n
+	}
+}
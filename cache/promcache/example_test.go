@@ -0,0 +1,29 @@
+package promcache_test
+
+import (
+	"fmt"
+
+	"github.com/mahdichaari01/go-cache/cache"
+	"github.com/mahdichaari01/go-cache/cache/promcache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func ExampleNewCollector() {
+	sessions, _ := cache.NewStringCache(100)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(promcache.NewCollector(sessions, "sessions"))
+
+	sessions.Set("alice", "token")
+	sessions.Get("alice")
+
+	families, _ := registry.Gather()
+	for _, family := range families {
+		fmt.Println(family.GetName(), family.GetMetric()[0].GetGauge().GetValue()+family.GetMetric()[0].GetCounter().GetValue())
+	}
+	// Output:
+	// gocache_capacity 100
+	// gocache_entries 1
+	// gocache_evictions_total 0
+	// gocache_hits_total 1
+	// gocache_misses_total 0
+}
@@ -0,0 +1,64 @@
+// Package promcache exposes the statistics of a cache as Prometheus metrics.
+// It is kept apart from the cache package so that only the programs importing it build the
+// Prometheus client
+package promcache
+
+import (
+	"github.com/mahdichaari01/go-cache/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Source is what the collector reads, any LruCache is a Source
+type Source interface {
+	Stats() cache.CacheStats
+}
+
+type collector struct {
+	source Source
+
+	capacity  *prometheus.Desc
+	entries   *prometheus.Desc
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+}
+
+// NewCollector returns a collector of the metrics of source, labelled with cache=name so that
+// several caches can be registered side by side:
+//   - gocache_capacity and gocache_entries gauges
+//   - gocache_hits_total, gocache_misses_total and gocache_evictions_total counters
+//
+// The counters come from Stats: a ResetStats shows up as a counter reset
+func NewCollector(source Source, name string) prometheus.Collector {
+	labels := prometheus.Labels{"cache": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("gocache", "", metric), help, nil, labels)
+	}
+	return &collector{
+		source:    source,
+		capacity:  desc("capacity", "Maximum number of entries."),
+		entries:   desc("entries", "Number of entries currently cached."),
+		hits:      desc("hits_total", "Gets that found their key."),
+		misses:    desc("misses_total", "Gets that missed their key."),
+		evictions: desc("evictions_total", "Entries evicted to make room for another one."),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.capacity
+	ch <- c.entries
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+}
+
+// Collect reads the counters, the length and the capacity in a single Stats call, under the lock
+// of the cache, so that they are consistent with each other
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.source.Stats()
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(stats.Capacity))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Len))
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions))
+}
@@ -0,0 +1,44 @@
+package promcache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mahdichaari01/go-cache/cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c, _ := cache.NewStringCache(2)
+	c.Set("key1", "value1")
+	c.Set("key2", "value2")
+	c.Get("key1")           // hit
+	c.Get("missing")        // miss
+	c.Set("key3", "value3") // evicts key2
+	c.Get("key2")           // miss
+
+	want := `
+# HELP gocache_capacity Maximum number of entries.
+# TYPE gocache_capacity gauge
+gocache_capacity{cache="sessions"} 2
+# HELP gocache_entries Number of entries currently cached.
+# TYPE gocache_entries gauge
+gocache_entries{cache="sessions"} 2
+# HELP gocache_evictions_total Entries evicted to make room for another one.
+# TYPE gocache_evictions_total counter
+gocache_evictions_total{cache="sessions"} 1
+# HELP gocache_hits_total Gets that found their key.
+# TYPE gocache_hits_total counter
+gocache_hits_total{cache="sessions"} 1
+# HELP gocache_misses_total Gets that missed their key.
+# TYPE gocache_misses_total counter
+gocache_misses_total{cache="sessions"} 2
+`
+	collector := NewCollector(c, "sessions")
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	if problems, err := testutil.CollectAndLint(collector); err != nil || len(problems) > 0 {
+		t.Errorf("lint: %v %v", problems, err)
+	}
+}
@@ -17,7 +17,7 @@ func main() {
 
 func runInteractiveMode() {
 	scanner := bufio.NewScanner(os.Stdin)
-	var cache *goCache.LruCache
+	var cache *goCache.StringCache
 	fmt.Println("Welcome to the interactive demo of go-cache")
 
 	for {
@@ -33,7 +33,7 @@ func runInteractiveMode() {
 			continue
 		}
 		// attempt cache creation
-		if cache, err = goCache.NewCache(i); err == nil {
+		if cache, err = goCache.NewStringCache(i); err == nil {
 			break
 		}
 